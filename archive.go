@@ -0,0 +1,144 @@
+package uuencode
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/text/transform"
+)
+
+// ArchiveReader reads the uuencoded contents of a stream as the entries of an
+// archive, like archive/tar.Reader. Next advances to the next entry and Read
+// reads its decoded bytes.
+type ArchiveReader struct {
+	pr  *PartReader
+	cur *Part
+}
+
+// NewArchiveReader returns ArchiveReader that reads from r. Any bytes that do
+// not belong to uuencoded contents are skipped.
+func NewArchiveReader(r io.Reader) *ArchiveReader {
+	return &ArchiveReader{pr: NewPartReader(r)}
+}
+
+// Next advances to the next entry and returns its parsed begin line. The unread
+// bytes of the current entry are discarded. It returns io.EOF at the end of
+// input.
+func (ar *ArchiveReader) Next() (*Header, error) {
+	p, err := ar.pr.NextPart()
+	if err != nil {
+		ar.cur = nil
+		return nil, err
+	}
+	ar.cur = p
+	h := p.Header
+	return &h, nil
+}
+
+// Read reads the decoded bytes of the current entry. It returns io.EOF at the
+// end of the entry, or before Next is called.
+func (ar *ArchiveReader) Read(b []byte) (int, error) {
+	if ar.cur == nil {
+		return 0, io.EOF
+	}
+	return ar.cur.Read(b)
+}
+
+// errNoHeader indicates ArchiveWriter is written before WriteHeader.
+var errNoHeader = errors.New("uuencode: write before WriteHeader")
+
+// ArchiveWriter writes entries as consecutive uuencoded contents into one
+// stream, like archive/tar.Writer. WriteHeader starts a new entry and Write
+// writes its bytes.
+type ArchiveWriter struct {
+	w   io.Writer
+	e   *Encode
+	cur io.WriteCloser
+}
+
+// NewArchiveWriter returns ArchiveWriter that writes into w. opts configure
+// the line format of every entry, the begin line options are ignored.
+func NewArchiveWriter(w io.Writer, opts ...EncodeOption) *ArchiveWriter {
+	return &ArchiveWriter{w: w, e: NewEncodeWith(opts...)}
+}
+
+// NewArchiveAppender returns ArchiveWriter that appends new entries to the
+// archive rws without re-encoding its existing entries, eg: an *os.File opened
+// with os.O_RDWR. The existing entries are read to check they are complete,
+// then the entries are written at the end of rws. An end of line is written
+// first if rws does not end with one. opts are like NewArchiveWriter.
+func NewArchiveAppender(rws io.ReadWriteSeeker, opts ...EncodeOption) (
+	*ArchiveWriter, error) {
+	if _, err := rws.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	ar := NewArchiveReader(rws)
+	for {
+		if _, err := ar.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(ioutil.Discard, ar); err != nil {
+			return nil, err
+		}
+	}
+	aw := NewArchiveWriter(rws, opts...)
+	end, err := rws.Seek(0, io.SeekEnd)
+	if err != nil || end == 0 {
+		return aw, err
+	}
+	if _, err = rws.Seek(-1, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	last := make([]byte, 1)
+	if _, err = io.ReadFull(rws, last); err != nil {
+		return nil, err
+	}
+	if last[0] != '\n' {
+		if _, err = io.WriteString(rws, aw.e.eol); err != nil {
+			return nil, err
+		}
+	}
+	return aw, nil
+}
+
+// WriteHeader finishes the current entry and starts a new one with the file
+// name and permission bits of h. Zero permission bits is written as 644.
+func (aw *ArchiveWriter) WriteHeader(h Header) error {
+	if err := aw.finish(); err != nil {
+		return err
+	}
+	mode := h.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+	aw.e.ResetFile(mode, h.Name)
+	aw.cur = transform.NewWriter(aw.w, aw.e)
+	return nil
+}
+
+// Write writes b into the current entry.
+func (aw *ArchiveWriter) Write(b []byte) (int, error) {
+	if aw.cur == nil {
+		return 0, errNoHeader
+	}
+	return aw.cur.Write(b)
+}
+
+// Close finishes the current entry. It does not close the underlying writer,
+// so WriteHeader may still continue the archive with more entries.
+func (aw *ArchiveWriter) Close() error {
+	return aw.finish()
+}
+
+// finish flushes the last line and the end marker of the current entry.
+func (aw *ArchiveWriter) finish() error {
+	if aw.cur == nil {
+		return nil
+	}
+	err := aw.cur.Close()
+	aw.cur = nil
+	return err
+}
@@ -0,0 +1,150 @@
+package uuencode_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+)
+
+func TestArchiveReader(t *testing.T) {
+	in := "junk\nbegin 600 a.txt\n#86)C\n`\nend\nbetween\n" +
+		"begin 755 b.txt\n&86)C9&5F\n`\nend\nbegin 644 c.txt\n#86)C\n`\nend\n"
+	ar := uuencode.NewArchiveReader(strings.NewReader(in))
+	if n, err := ar.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Error("Read before Next got: ", n, err)
+	}
+	var got []string
+	for i := 0; ; i++ {
+		h, err := ar.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			// leave the entry unread.
+			got = append(got, h.Name)
+			continue
+		}
+		b, err := ioutil.ReadAll(ar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, h.Name+" "+h.Mode.String()+" "+string(b))
+	}
+	want := []string{"a.txt -rw------- abc", "b.txt",
+		"c.txt -rw-r--r-- abc"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestArchiveReaderFail(t *testing.T) {
+	in := "begin 644 a.txt\n#86)C\nbad line\n`\nend\n"
+	ar := uuencode.NewArchiveReader(strings.NewReader(in))
+	if _, err := ar.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(ar); !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err)
+	}
+	if _, err := ar.Next(); !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err)
+	}
+}
+
+func TestArchiveWriter(t *testing.T) {
+	var buf strings.Builder
+	aw := uuencode.NewArchiveWriter(&buf, uuencode.WithEOL("\r\n"),
+		uuencode.WithFilename("ignored"))
+	if _, err := aw.Write([]byte("abc")); err == nil {
+		t.Error("Expected error but return nil")
+	}
+	entries := []struct {
+		h    uuencode.Header
+		data string
+	}{
+		{uuencode.Header{Name: "a.txt", Mode: 0600}, "abc"},
+		{uuencode.Header{Name: "b.txt"}, "abcdef"},
+		{uuencode.Header{Name: "empty", Mode: 0755}, ""},
+	}
+	for _, e := range entries {
+		if err := aw.WriteHeader(e.h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(aw, e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "begin 600 a.txt\r\n#86)C\r\n`\r\nend\r\n" +
+		"begin 644 b.txt\r\n&86)C9&5F\r\n`\r\nend\r\n" +
+		"begin 755 empty\r\n`\r\nend\r\n"
+	if diff := pretty.Compare(buf.String(), want); diff != "" {
+		t.Error(diff)
+	}
+	// round trip through ArchiveReader.
+	ar := uuencode.NewArchiveReader(strings.NewReader(buf.String()))
+	for _, e := range entries {
+		h, err := ar.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(ar)
+		if err != nil || h.Name != e.h.Name || string(b) != e.data {
+			t.Errorf("Got: %+v %q %v", h, b, err)
+		}
+	}
+}
+
+func TestArchiveAppender(t *testing.T) {
+	f, err := ioutil.TempFile("", "uuencode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	// the trailing text without end of line is kept.
+	if _, err = io.WriteString(f,
+		"begin 600 a.txt\n#86)C\n`\nend\ntrailer"); err != nil {
+		t.Fatal(err)
+	}
+	aw, err := uuencode.NewArchiveAppender(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = aw.WriteHeader(uuencode.Header{Name: "b.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.WriteString(aw, "abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	if err = aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "begin 600 a.txt\n#86)C\n`\nend\ntrailer\n" +
+		"begin 644 b.txt\n&86)C9&5F\n`\nend\n"
+	if diff := pretty.Compare(string(b), want); diff != "" {
+		t.Error(diff)
+	}
+	// incomplete existing entry is not appended to.
+	if _, err = f.WriteString("begin 644 c.txt\n#86)C\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uuencode.NewArchiveAppender(f); !errors.Is(err,
+		uuencode.ErrMissingEnd) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrMissingEnd)
+	}
+}
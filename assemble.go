@@ -0,0 +1,98 @@
+package uuencode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrIncomplete indicates some fragments of a multi-part post are missing.
+var ErrIncomplete = errors.New("uuencode: missing fragments")
+
+// Assembler reassembles one uuencoded content posted as several messages, eg:
+// Usenet "part 1/3" posts, where only the first fragment has the begin line
+// and only the last has the end marker line. Fragments can be added in any
+// order.
+type Assembler struct {
+	total int
+	frags map[int][]byte
+}
+
+// NewAssembler returns Assembler for the content split into total fragments.
+func NewAssembler(total int) *Assembler {
+	return &Assembler{total: total, frags: make(map[int][]byte)}
+}
+
+// Add adds the message text of the fragment numbered index, starts from 1. The
+// lines before and after the uuencoded lines, eg: mail headers or signatures,
+// are ignored. Adding the same index again replaces the previous fragment.
+func (a *Assembler) Add(index int, fragment []byte) error {
+	if index < 1 || index > a.total {
+		return fmt.Errorf("uuencode: fragment %d out of range 1-%d", index,
+			a.total)
+	}
+	a.frags[index] = append([]byte(nil), fragment...)
+	return nil
+}
+
+// Missing returns the indexes of the fragments not added yet.
+func (a *Assembler) Missing() []int {
+	var missing []int
+	for i := 1; i <= a.total; i++ {
+		if _, ok := a.frags[i]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// Part returns the reassembled uuencoded content. It returns error wrapping
+// ErrIncomplete if any fragment is missing, or ErrBadUUDec if no begin line is
+// found.
+func (a *Assembler) Part() (*Part, error) {
+	if missing := a.Missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrIncomplete, missing)
+	}
+	var buf bytes.Buffer
+	var begun, ended bool
+	for i := 1; i <= a.total && !ended; i++ {
+		// span is the lines from the first to the last uuencoded line of the
+		// fragment. Lines outside it, eg: mail headers and signatures, are
+		// dropped, but invalid lines inside it are kept so the decoder reports
+		// them instead of losing data silently.
+		var span [][]byte
+		var n int
+		sc := newLineScanner(bytes.NewReader(a.frags[i]))
+		for {
+			line, _, err := sc.next()
+			isBegin := bytes.HasPrefix(line, []byte(uuBeginMarker+" "))
+			if !begun {
+				begun = isBegin
+			}
+			if begun && !ended && line != nil {
+				uu := validBodyLine(line) || isBegin || string(line) == "`" ||
+					string(line) == uuEndMarker
+				if uu || len(span) > 0 {
+					span = append(span, append([]byte(nil), line...))
+				}
+				if uu {
+					n = len(span)
+				}
+				ended = string(line) == uuEndMarker
+			}
+			if err != nil {
+				break
+			}
+		}
+		for _, line := range span[:n] {
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+	}
+	p, err := NewPartReader(&buf).NextPart()
+	if err == io.EOF {
+		return nil, ErrBadUUDec
+	}
+	return p, err
+}
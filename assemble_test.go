@@ -0,0 +1,90 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+)
+
+func TestAssembler(t *testing.T) {
+	src := make([]byte, 2000)
+	for i := range src {
+		src[i] = byte(i * 3)
+	}
+	lines := strings.SplitAfter(uuencode.EncodeToString(src), "\n")
+	// split into 3 posts with mail headers and signatures.
+	var posts []string
+	for i, n := 0, (len(lines)+2)/3; i < len(lines); i += n {
+		end := i + n
+		if end > len(lines) {
+			end = len(lines)
+		}
+		posts = append(posts, "Subject: big.bin\n\n"+
+			strings.Join(lines[i:end], "")+"-- \nsignature\n")
+	}
+	a := uuencode.NewAssembler(len(posts))
+	for _, i := range []int{3, 1} {
+		if err := a.Add(i, []byte(posts[i-1])); err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+	}
+	if _, err := a.Part(); !errors.Is(err, uuencode.ErrIncomplete) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrIncomplete)
+	}
+	if err := a.Add(4, nil); err == nil {
+		t.Error("Expecting error for out of range fragment")
+	}
+	if err := a.Add(2, []byte(posts[1])); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	p, err := a.Part()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	got, err := ioutil.ReadAll(p)
+	if err != nil {
+		t.Fatal("err at read all:", err)
+	}
+	if !bytes.Equal(got, src) || p.Header.Name != "filename" {
+		t.Error("reassembled contents mismatch")
+	}
+}
+
+func TestAssemblerNoBegin(t *testing.T) {
+	a := uuencode.NewAssembler(1)
+	a.Add(1, []byte("#86)C\n`\nend\n"))
+	if _, err := a.Part(); !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
+	}
+}
+
+func TestAssemblerBadLine(t *testing.T) {
+	src := bytes.Repeat([]byte("0123456789"), 40)
+	lines := strings.SplitAfter(uuencode.EncodeToString(src), "\n")
+	// corrupt a body line in the middle of the second post.
+	bad := strings.Replace(lines[5], "M", "m", 1)
+	posts := []string{
+		"Subject: 1/2\n\n" + strings.Join(lines[:4], "") + "-- \nsig\n",
+		"Subject: 2/2\n\n" + strings.Join(lines[4:5], "") + bad +
+			strings.Join(lines[6:], "") + "-- \nsig\n",
+	}
+	a := uuencode.NewAssembler(len(posts))
+	for i, post := range posts {
+		if err := a.Add(i+1, []byte(post)); err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+	}
+	p, err := a.Part()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	_, err = ioutil.ReadAll(p)
+	var lerr *uuencode.BadLineError
+	if !errors.As(err, &lerr) || lerr.Line != strings.TrimSuffix(bad, "\n") {
+		t.Error("Got: ", err, " Expecting: *uuencode.BadLineError")
+	}
+}
@@ -0,0 +1,124 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+const tBenchLen = 1 << 20
+
+func tstBenchData(n int) []byte {
+	src := make([]byte, n)
+	for i := range src {
+		src[i] = byte(i * 7)
+	}
+	return src
+}
+
+func BenchmarkEncode(b *testing.B) {
+	src := tstBenchData(tBenchLen)
+	e := uuencode.NewEncode(true, "\n")
+	b.SetBytes(tBenchLen)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := transform.NewReader(bytes.NewReader(src), e)
+		if _, err := io.Copy(ioutil.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	src := []byte(uuencode.EncodeToString(tstBenchData(tBenchLen)))
+	d := uuencode.NewDecode()
+	b.SetBytes(tBenchLen)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := transform.NewReader(bytes.NewReader(src), d)
+		if _, err := io.Copy(ioutil.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMultiDecode(b *testing.B) {
+	src := []byte(uuencode.EncodeToString(tstBenchData(tBenchLen)))
+	b.SetBytes(tBenchLen)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d, _, ch := uuencode.NewMultiDecode()
+		go func() {
+			for r := range ch {
+				io.Copy(ioutil.Discard, r)
+			}
+		}()
+		r := transform.NewReader(bytes.NewReader(src), d)
+		if _, err := io.Copy(ioutil.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+		d.Close()
+	}
+}
+
+func BenchmarkPartReader(b *testing.B) {
+	src := []byte(uuencode.EncodeToString(tstBenchData(tBenchLen)))
+	b.SetBytes(tBenchLen)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pr := uuencode.NewPartReader(bytes.NewReader(src))
+		p, err := pr.NextPart()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(ioutil.Discard, p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestStreamingAllocs checks the allocations do not grow with the input size,
+// so memory use only depends on the line length.
+func TestStreamingAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable with race detector")
+	}
+	small, big := tstBenchData(4<<10), tstBenchData(256<<10)
+	encSmall := []byte(uuencode.EncodeToString(small))
+	encBig := []byte(uuencode.EncodeToString(big))
+	tsts := []struct {
+		name       string
+		run        func(src []byte)
+		small, big []byte
+	}{
+		{"encode", func(src []byte) {
+			r := transform.NewReader(bytes.NewReader(src),
+				uuencode.NewEncode(true, "\n"))
+			io.Copy(ioutil.Discard, r)
+		}, small, big},
+		{"decode", func(src []byte) {
+			r := transform.NewReader(bytes.NewReader(src), uuencode.NewDecode())
+			io.Copy(ioutil.Discard, r)
+		}, encSmall, encBig},
+		{"part reader", func(src []byte) {
+			p, _ := uuencode.NewPartReader(bytes.NewReader(src)).NextPart()
+			io.Copy(ioutil.Discard, p)
+		}, encSmall, encBig},
+	}
+	for _, tst := range tsts {
+		a := testing.AllocsPerRun(5, func() { tst.run(tst.small) })
+		b := testing.AllocsPerRun(5, func() { tst.run(tst.big) })
+		if b > a {
+			t.Errorf("%s: allocs grow from %v to %v with input size", tst.name,
+				a, b)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package uuencode
+
+// Blob is bytes that are marshaled as a uuencoded block, so uuencoded payloads
+// can be embedded in JSON, XML or other text formats handled by
+// encoding.TextMarshaler.
+type Blob []byte
+
+// MarshalText implements encoding.TextMarshaler. The result is the same as
+// EncodeToString.
+func (b Blob) MarshalText() ([]byte, error) {
+	return []byte(EncodeToString(b)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It decodes the first
+// uuencoded content of text like DecodeString.
+func (b *Blob) UnmarshalText(text []byte) error {
+	d, err := DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*b = d
+	return nil
+}
@@ -0,0 +1,51 @@
+package uuencode_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+)
+
+func TestBlob(t *testing.T) {
+	type config struct {
+		Name string
+		Data uuencode.Blob
+	}
+	in := config{Name: "cfg", Data: uuencode.Blob("abcdef")}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// json escapes & as \u0026.
+	want := `{"Name":"cfg","Data":"begin 644 filename\n\u002686)C9\u00265F\n` +
+		"`" + `\nend\n"}`
+	if diff := pretty.Compare(string(b), want); diff != "" {
+		t.Error(diff)
+	}
+	var out config
+	if err = json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if diff := pretty.Compare(out, in); diff != "" {
+		t.Error(diff)
+	}
+	x, err := xml.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = config{}
+	if err = xml.Unmarshal(x, &out); err != nil {
+		t.Fatal(err)
+	}
+	if diff := pretty.Compare(out, in); diff != "" {
+		t.Error(diff)
+	}
+	err = json.Unmarshal([]byte(`{"Data":"not uuencoded"}`), &out)
+	if !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err)
+	}
+}
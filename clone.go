@@ -0,0 +1,42 @@
+package uuencode
+
+// Clone returns a new Encode with the same begin line, line format and
+// OnProgress hook as e, in the initial state. Encode is not safe for concurrent
+// use, so each goroutine should use its own clone. Clone does not modify e and
+// may be called concurrently as long as e is not transforming or being
+// configured at the same time.
+func (e *Encode) Clone() *Encode {
+	c := *e
+	c.Reset()
+	return &c
+}
+
+// Clone returns a new single decoding Decode with the same options and hooks
+// as d, in the initial state. Decode is not safe for concurrent use, so each
+// goroutine should use its own clone. Clone does not modify d and may be called
+// concurrently as long as d is not being configured at the same time.
+//
+// The clone of multiple uuencoded contents decoding Decode does single
+// decoding, use NewMultiDecodeWith for another multiple decoding.
+func (d *Decode) Clone() *Decode {
+	c := &Decode{
+		uuBodyDec: uuBodyDec{repad: d.repad, rejoin: d.rejoin,
+			bodyOnly: d.bodyOnly, alpha: d.baseAlpha},
+		onHeader:   d.onHeader,
+		onWarning:  d.onWarning,
+		onTrace:    d.onTrace,
+		onProgress: d.onProgress,
+		lenient:    d.lenient,
+		concat:     d.concat,
+		stripBOM:   d.stripBOM,
+		maxPart:    d.maxPart,
+		maxTotal:   d.maxTotal,
+		maxParts:   d.maxParts,
+		pipeBuf:    d.pipeBuf,
+		textEOL:    d.textEOL,
+		newHash:    d.newHash,
+		baseAlpha:  d.baseAlpha,
+		ctx:        d.ctx,
+	}
+	return c
+}
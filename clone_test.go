@@ -0,0 +1,80 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestEncodeClone(t *testing.T) {
+	e := uuencode.NewEncodeWith(uuencode.WithFilename("pp.txt"),
+		uuencode.WithEOL("\r\n"))
+	// a used Encode is cloned in initial state.
+	if _, _, err := transform.String(e, "used"); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	want, _, err := transform.String(e, "I love you forever.")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(c *uuencode.Encode) {
+			defer wg.Done()
+			got, _, err := transform.String(c, "I love you forever.")
+			if err != nil {
+				t.Error("Expecting non-error but got err:", err)
+			}
+			if diff := pretty.Compare(got, want); diff != "" {
+				t.Errorf("Diff: %s", diff)
+			}
+		}(e.Clone())
+	}
+	wg.Wait()
+}
+
+func TestDecodeClone(t *testing.T) {
+	var mu sync.Mutex
+	var names []string
+	d := uuencode.NewDecodeWith(uuencode.WithRepad(true),
+		uuencode.WithMaxPartSize(10))
+	d.OnHeader(func(h uuencode.Header) {
+		mu.Lock()
+		names = append(names, h.Name)
+		mu.Unlock()
+	})
+	c := d.Clone()
+	got, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(
+		"begin 644 a.txt\n#86)C\n`\nend\n"), c))
+	if err != nil || string(got) != "abc" {
+		t.Error("Got: ", string(got), err)
+	}
+	if len(names) != 1 || names[0] != "a.txt" {
+		t.Error("Got names: ", names)
+	}
+	// options are cloned too.
+	_, err = ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(
+		uuencode.EncodeToString(make([]byte, 100))), d.Clone()))
+	if !errors.Is(err, uuencode.ErrTooLarge) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrTooLarge)
+	}
+}
+
+func TestDecodeCloneStripBOM(t *testing.T) {
+	d := uuencode.NewDecodeWith(uuencode.WithStripBOM(true))
+	got, _, err := transform.String(d.Clone(),
+		"\xef\xbb\xbftext\nbegin 644 a\n#86)C\n`\nend\n")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(got, "text\nabc"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
@@ -0,0 +1,315 @@
+// Command uudecode decodes the uuencoded contents of files into the files named
+// at their begin lines, compatible with the POSIX uudecode utility.
+//
+// Usage:
+//
+//	uudecode [-o outfile] [-p] [file...]
+//	uudecode -r mbox outdir
+//
+// The input is every file, or standard input if no file is given. Every
+// uuencoded content of the input is decoded, as well as every base64 content
+// between begin-base64 and ==== lines written by uuencode -m. -o writes the
+// decoded contents into outfile instead and -p writes them to standard
+// output. The begin line name /dev/stdout also writes to standard output. A
+// file whose content fails to decode is removed. The exit status is 0 on
+// success and 1 on error, including input without any uuencoded content.
+//
+// -r extracts every uuencoded content of every message of mbox into outdir.
+// The begin line names are stripped of their directories and renamed on
+// collision, eg: file(1).txt, so every attachment is kept.
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	uu "github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+// base64Begin is the begin line prefix of base64 content.
+const base64Begin = "begin-base64 "
+
+// errNoBegin indicates the input has no uuencoded content.
+var errNoBegin = errors.New("no begin line")
+
+// nopWriteCloser is io.WriteCloser that Close does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("uudecode", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	outfile := fs.String("o", "", "write to `outfile`")
+	toStdout := fs.Bool("p", false, "write to standard output")
+	mbox := fs.Bool("r", false, "extract all messages of mbox into outdir")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: uudecode [-o outfile] [-p] [file...]")
+		fmt.Fprintln(stderr, "       uudecode -r mbox outdir")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if *mbox {
+		if fs.NArg() != 2 || *toStdout || *outfile != "" {
+			fs.Usage()
+			return 1
+		}
+		return runMbox(fs.Arg(0), fs.Arg(1), stderr)
+	}
+	var create func(h uu.Header) (io.WriteCloser, error)
+	switch {
+	case *toStdout:
+		create = func(uu.Header) (io.WriteCloser, error) {
+			return nopWriteCloser{stdout}, nil
+		}
+	case *outfile != "":
+		// every content is written into the same outfile.
+		var f *os.File
+		defer func() {
+			if f != nil {
+				f.Close()
+			}
+		}()
+		create = func(h uu.Header) (io.WriteCloser, error) {
+			if f == nil {
+				var err error
+				if f, err = createFile(*outfile, h.Mode); err != nil {
+					return nil, err
+				}
+			}
+			return nopWriteCloser{f}, nil
+		}
+	default:
+		create = func(h uu.Header) (io.WriteCloser, error) {
+			if h.Name == "/dev/stdout" {
+				return nopWriteCloser{stdout}, nil
+			} else if h.Name == "" {
+				return nil, errors.New("missing file name")
+			}
+			return createFile(h.Name, h.Mode)
+		}
+	}
+	status := 0
+	decodeInput := func(name string, r io.Reader) {
+		if err := decode(r, create); err != nil {
+			fmt.Fprintf(stderr, "uudecode: %s: %v\n", name, err)
+			status = 1
+		}
+	}
+	if fs.NArg() == 0 {
+		decodeInput("stdin", stdin)
+	}
+	for _, name := range fs.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(stderr, "uudecode:", err)
+			status = 1
+			continue
+		}
+		decodeInput(name, f)
+		f.Close()
+	}
+	return status
+}
+
+// createFile creates the file name with permission bits mode, or 0644 if mode
+// is zero. The setuid, setgid and sticky bits are dropped.
+func createFile(name string, mode os.FileMode) (*os.File, error) {
+	if mode = mode.Perm(); mode == 0 {
+		mode = 0644
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return nil, err
+	}
+	// the permission bits of existing file or umask are overridden.
+	if err = f.Chmod(mode); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// decode decodes every uuencoded and base64 content of r into the writer
+// returned by create.
+func decode(r io.Reader, create func(h uu.Header) (io.WriteCloser,
+	error)) error {
+	br := bufio.NewReader(r)
+	var found bool
+	for {
+		ur := &uuReader{br: br}
+		ok, err := decodeUU(ur, create)
+		found = found || ok
+		if err != nil {
+			return err
+		} else if !ur.base64 {
+			break
+		}
+		found = true
+		if err = decodeBase64(br, create); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return errNoBegin
+	}
+	return nil
+}
+
+// uuReader reads br up to the begin line of base64 content.
+type uuReader struct {
+	br     *bufio.Reader
+	line   []byte // unread rest of the last read line
+	mid    bool   // the next read is not at the line start
+	base64 bool   // stopped at the begin line of base64 content
+	err    error
+}
+
+func (r *uuReader) Read(p []byte) (int, error) {
+	if len(r.line) == 0 {
+		if r.base64 {
+			return 0, io.EOF
+		} else if r.err != nil {
+			return 0, r.err
+		}
+		if !r.mid {
+			b, _ := r.br.Peek(len(base64Begin))
+			if string(b) == base64Begin {
+				r.base64 = true
+				return 0, io.EOF
+			}
+		}
+		line, err := r.br.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull {
+			r.err = err
+		}
+		r.line = line
+		r.mid = len(line) > 0 && line[len(line)-1] != '\n'
+		if len(line) == 0 {
+			return 0, r.err
+		}
+	}
+	n := copy(p, r.line)
+	r.line = r.line[n:]
+	return n, nil
+}
+
+// decodeUU decodes every uuencoded content of r into the writer returned by
+// create and reports whether any is found.
+func decodeUU(r io.Reader, create func(h uu.Header) (io.WriteCloser,
+	error)) (bool, error) {
+	d, cancel, ch := uu.NewMultiDecodeParts()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(ioutil.Discard, transform.NewReader(r, d))
+		d.Close()
+		errc <- err
+	}()
+	var found bool
+	var werr error
+	for p := range ch {
+		found = true
+		if werr == nil {
+			if werr = write(p.Header, p, create); werr != nil {
+				// stop the decoding, the remaining contents are dropped.
+				cancel()
+			}
+		}
+		p.Close()
+	}
+	err := <-errc
+	if werr != nil {
+		return found, werr
+	}
+	return found, err
+}
+
+// decodeBase64 decodes the base64 content of br, from its begin-base64 line
+// to its ==== line, into the writer returned by create.
+func decodeBase64(br *bufio.Reader, create func(h uu.Header) (io.WriteCloser,
+	error)) error {
+	line, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	raw := strings.TrimRight(line, "\r\n")
+	f := strings.SplitN(strings.TrimPrefix(raw, base64Begin), " ", 2)
+	if len(f) != 2 || f[1] == "" {
+		return fmt.Errorf("bad begin line: %q", raw)
+	}
+	mode, err := strconv.ParseUint(f[0], 8, 32)
+	if err != nil || mode&^07777 != 0 {
+		return fmt.Errorf("bad begin line: %q", raw)
+	}
+	h := uu.Header{Name: f[1], Mode: os.FileMode(mode).Perm(), Raw: raw}
+	return write(h, &base64Reader{br: br}, create)
+}
+
+// base64Reader decodes the base64 lines of br up to the ==== line.
+type base64Reader struct {
+	br  *bufio.Reader
+	buf []byte
+	end bool
+}
+
+func (r *base64Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.end {
+			return 0, io.EOF
+		}
+		line, err := r.br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return 0, errors.New("missing ==== line")
+		} else if err != nil && err != io.EOF {
+			return 0, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "====" {
+			r.end = true
+			continue
+		}
+		if r.buf, err = base64.StdEncoding.DecodeString(line); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// write copies the decoded content r with header h into the writer returned
+// by create. The file created for r is removed if r fails.
+func write(h uu.Header, r io.Reader, create func(h uu.Header) (io.WriteCloser,
+	error)) error {
+	w, err := create(h)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, r); err != nil {
+		w.Close()
+		if f, ok := w.(*os.File); ok {
+			os.Remove(f.Name())
+		}
+		return err
+	}
+	return w.Close()
+}
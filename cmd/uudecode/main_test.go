@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uudecode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	in := "junk\nbegin 600 " + a + "\n#86)C\n`\nend\nbegin 755 " + b +
+		"\n&86)C9&5F\n`\nend\n"
+	inFile := filepath.Join(dir, "in.uu")
+	if err = ioutil.WriteFile(inFile, []byte(in), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	tsts := []struct {
+		args   []string
+		stdin  string
+		status int
+		stdout string
+		files  map[string]string
+		modes  map[string]os.FileMode
+	}{
+		{nil, in, 0, "", map[string]string{a: "abc", b: "abcdef"},
+			map[string]os.FileMode{a: 0600, b: 0755}},
+		{[]string{inFile}, "", 0, "", map[string]string{a: "abc",
+			b: "abcdef"}, nil},
+		{[]string{"-p", inFile}, "", 0, "abcabcdef", nil, nil},
+		{[]string{"-o", out}, in, 0, "", map[string]string{out: "abcabcdef"},
+			map[string]os.FileMode{out: 0600}},
+		{nil, "begin 644 /dev/stdout\n#86)C\n`\nend\n", 0, "abc", nil, nil},
+		{nil, "no uuencode\n", 1, "", nil, nil},
+		{nil, "begin 644\n#86)C\n`\nend\n", 1, "", nil, nil},
+		{[]string{"unknown file", inFile}, "", 1, "",
+			map[string]string{a: "abc", b: "abcdef"}, nil},
+		{[]string{"-x"}, "", 1, "", nil, nil},
+	}
+	for i, tst := range tsts {
+		os.Remove(a)
+		os.Remove(b)
+		os.Remove(out)
+		var stdout, stderr bytes.Buffer
+		status := run(tst.args, strings.NewReader(tst.stdin), &stdout,
+			&stderr)
+		if status != tst.status || (status != 0) != (stderr.Len() > 0) {
+			t.Errorf("%d Got status %d: %s", i, status, stderr.String())
+		}
+		if stdout.String() != tst.stdout {
+			t.Errorf("%d Got stdout: %q", i, stdout.String())
+		}
+		for name, content := range tst.files {
+			got, err := ioutil.ReadFile(name)
+			if err != nil || string(got) != content {
+				t.Errorf("%d Got %s: %q %v", i, name, got, err)
+			}
+		}
+		for name, mode := range tst.modes {
+			fi, err := os.Stat(name)
+			if err != nil {
+				t.Errorf("%d Got %s: %v", i, name, err)
+			} else if fi.Mode() != mode {
+				t.Errorf("%d Got %s mode: %v", i, name, fi.Mode())
+			}
+		}
+	}
+}
+
+func TestRunBase64(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uudecode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	// the output of uuencode -m for "hello\n" and "abc".
+	b64 := "begin-base64 600 " + a + "\naGVsbG8K\n====\n"
+	uue := "begin 755 " + b + "\n#86)C\n`\nend\n"
+	tsts := []struct {
+		stdin  string
+		status int
+		files  map[string]string
+	}{
+		{b64, 0, map[string]string{a: "hello\n"}},
+		{"junk\n" + uue + "text\n" + b64 + "junk\n", 0,
+			map[string]string{a: "hello\n", b: "abc"}},
+		{b64 + uue, 0, map[string]string{a: "hello\n", b: "abc"}},
+		{"begin-base64 600 " + a + "\r\naGVs\r\nbG8K\r\n====\r\n", 0,
+			map[string]string{a: "hello\n"}},
+		// failed contents leave no file.
+		{"begin-base64 600 " + a + "\naGVsbG8K\n*bad*\n====\n", 1, nil},
+		{"begin-base64 600 " + a + "\naGVsbG8K\n", 1, nil},
+		{"begin 600 " + a + "\n#86)C\n&86)C9&5F!\n`\nend\n", 1, nil},
+		{"begin-base64 9999 " + a + "\n====\n", 1, nil},
+		{"begin-base64 600\n====\n", 1, nil},
+	}
+	for i, tst := range tsts {
+		os.Remove(a)
+		os.Remove(b)
+		var stdout, stderr bytes.Buffer
+		status := run(nil, strings.NewReader(tst.stdin), &stdout, &stderr)
+		if status != tst.status {
+			t.Errorf("%d Got status %d: %s", i, status, stderr.String())
+		}
+		for _, name := range []string{a, b} {
+			got, err := ioutil.ReadFile(name)
+			content, ok := tst.files[name]
+			if ok != (err == nil) || string(got) != content {
+				t.Errorf("%d Got %s: %q %v", i, name, got, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sanylcs/uuencode/uumail"
+	"github.com/sanylcs/uuencode/uuutil"
+	"golang.org/x/net/context"
+)
+
+// runMbox extracts every uuencoded content of the messages of mbox into dir
+// and returns the exit status.
+func runMbox(mbox, dir string, stderr io.Writer) int {
+	f, err := os.Open(mbox)
+	if err != nil {
+		fmt.Fprintln(stderr, "uudecode:", err)
+		return 1
+	}
+	defer f.Close()
+	// uuutil.Parse creates missing dir without the search permission.
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintln(stderr, "uudecode:", err)
+		return 1
+	}
+	status := 0
+	mr := uumail.NewMboxReader(f)
+	for {
+		index, msg, err := mr.NextRaw()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			fmt.Fprintln(stderr, "uudecode:", err)
+			return 1
+		}
+		files, err := uuutil.Parse(context.Background(), nil, dir,
+			bytes.NewReader(msg),
+			uuutil.WithCollision(uuutil.CollisionRename))
+		if err != nil {
+			fmt.Fprintf(stderr, "uudecode: message %d: %v\n", index, err)
+			status = 1
+		}
+		for _, ef := range files {
+			if ef.Err != nil {
+				fmt.Fprintf(stderr, "uudecode: message %d: %s: %v\n", index,
+					ef.Name, ef.Err)
+				status = 1
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunMbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uudecode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mbox := filepath.Join(dir, "mbox")
+	in := "From a@example.com Mon Jan  1 00:00:00 2001\nSubject: 1\n\n" +
+		"begin 644 ../a.txt\n#86)C\n`\nend\n\n" +
+		"From b@example.com Mon Jan  1 00:00:00 2001\nSubject: 2\n\n" +
+		"begin 644 a.txt\n&86)C9&5F\n`\nend\nbegin 644 b.txt\n#86)C\n"
+	if err = ioutil.WriteFile(mbox, []byte(in), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"-r", mbox, out}, nil, &stdout, &stderr)
+	// the last content misses the end marker.
+	if status != 1 || !strings.Contains(stderr.String(), "message 1") {
+		t.Errorf("Got status %d: %s", status, stderr.String())
+	}
+	want := map[string]string{"a.txt": "abc", "a(1).txt": "abcdef"}
+	for name, content := range want {
+		b, err := ioutil.ReadFile(filepath.Join(out, name))
+		if err != nil || string(b) != content {
+			t.Errorf("Got %s: %q %v", name, b, err)
+		}
+	}
+	for _, args := range [][]string{{"-r", mbox}, {"-r", "-p", mbox, out},
+		{"-r", "unknown file", out}} {
+		stderr.Reset()
+		if status = run(args, nil, &stdout, &stderr); status != 1 ||
+			stderr.Len() == 0 {
+			t.Errorf("%q Got status %d", args, status)
+		}
+	}
+}
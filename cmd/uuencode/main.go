@@ -0,0 +1,110 @@
+// Command uuencode encodes a file into uuencode format, compatible with the
+// POSIX uuencode utility.
+//
+// Usage:
+//
+//	uuencode [-m] [file] name
+//
+// The input is file, or standard input if file is not given. The output is
+// written to standard output with name at the begin line. -m encodes in base64
+// format (begin-base64). The exit status is 0 on success and 1 on error.
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	uu "github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+// stdinMode is the permission bits of the begin line when input is standard
+// input.
+const stdinMode = 0644
+
+// base64Line is the number of input bytes of each base64 line.
+const base64Line = 45
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("uuencode", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	useBase64 := fs.Bool("m", false, "encode in base64 format")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: uuencode [-m] [file] name")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	var r io.Reader
+	var mode os.FileMode
+	switch fs.NArg() {
+	case 1:
+		r, mode = stdin, stdinMode
+	case 2:
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, "uuencode:", err)
+			return 1
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			fmt.Fprintln(stderr, "uuencode:", err)
+			return 1
+		}
+		r, mode = f, fi.Mode()
+	default:
+		fs.Usage()
+		return 1
+	}
+	name := fs.Arg(fs.NArg() - 1)
+	var err error
+	if *useBase64 {
+		err = encodeBase64(stdout, r, mode, name)
+	} else {
+		e := uu.NewEncodeWith(uu.WithFilename(name), uu.WithMode(mode))
+		_, err = io.Copy(stdout, transform.NewReader(r, e))
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "uuencode:", err)
+		return 1
+	}
+	return 0
+}
+
+// encodeBase64 writes r into w in the base64 format of POSIX uuencode -m.
+func encodeBase64(w io.Writer, r io.Reader, mode os.FileMode,
+	name string) error {
+	_, err := fmt.Fprintf(w, "begin-base64 %03o %s\n", mode.Perm(), name)
+	if err != nil {
+		return err
+	}
+	in := make([]byte, base64Line)
+	out := make([]byte, base64.StdEncoding.EncodedLen(base64Line)+1)
+	for {
+		n, rerr := io.ReadFull(r, in)
+		if n > 0 {
+			m := base64.StdEncoding.EncodedLen(n)
+			base64.StdEncoding.Encode(out, in[:n])
+			out[m] = '\n'
+			if _, err = w.Write(out[:m+1]); err != nil {
+				return err
+			}
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		} else if rerr != nil {
+			return rerr
+		}
+	}
+	_, err = io.WriteString(w, "====\n")
+	return err
+}
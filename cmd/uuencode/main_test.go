@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uuencode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "in.txt")
+	if err = ioutil.WriteFile(file, []byte("abcdef"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chmod(file, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tsts := []struct {
+		args   []string
+		stdin  string
+		status int
+		out    string
+	}{
+		{[]string{"a.txt"}, "abc", 0, "begin 644 a.txt\n#86)C\n`\nend\n"},
+		{[]string{file, "b.txt"}, "", 0,
+			"begin 600 b.txt\n&86)C9&5F\n`\nend\n"},
+		{[]string{"-m", "a.txt"}, "abc", 0,
+			"begin-base64 644 a.txt\nYWJj\n====\n"},
+		{[]string{"-m", "a.txt"}, strings.Repeat("a", 46), 0,
+			"begin-base64 644 a.txt\n" + strings.Repeat("YWFh", 15) +
+				"\nYQ==\n====\n"},
+		{[]string{"-m", "a.txt"}, "", 0, "begin-base64 644 a.txt\n====\n"},
+		{nil, "", 1, ""},
+		{[]string{"a", "b", "c"}, "", 1, ""},
+		{[]string{"-x", "a"}, "", 1, ""},
+		{[]string{"unknown file", "a"}, "", 1, ""},
+	}
+	for i, tst := range tsts {
+		var stdout, stderr bytes.Buffer
+		status := run(tst.args, strings.NewReader(tst.stdin), &stdout,
+			&stderr)
+		if status != tst.status || (status != 0) != (stderr.Len() > 0) {
+			t.Errorf("%d Got status %d: %s", i, status, stderr.String())
+		}
+		if diff := pretty.Compare(stdout.String(), tst.out); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+	}
+}
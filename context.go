@@ -0,0 +1,63 @@
+package uuencode
+
+import (
+	"io"
+
+	"golang.org/x/net/context"
+)
+
+// cancelError is returned when decoding is canceled by context. It matches
+// ErrUuCancel with errors.Is and unwraps to the context error.
+type cancelError struct {
+	err error
+}
+
+func (e *cancelError) Error() string {
+	return ErrUuCancel.Error() + ": " + e.err.Error()
+}
+
+// Unwrap returns the context error.
+func (e *cancelError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is ErrUuCancel.
+func (e *cancelError) Is(target error) bool {
+	return target == ErrUuCancel
+}
+
+// cancelErr returns the error for canceled decoding.
+func (d *Decode) cancelErr() error {
+	if d.ctx != nil && d.ctx.Err() != nil {
+		return &cancelError{err: d.ctx.Err()}
+	}
+	return ErrUuCancel
+}
+
+// NewDecodeContext is like NewDecodeWith but Transform fails once ctx is done.
+// The returned error matches both ErrUuCancel and ctx.Err() with errors.Is.
+func NewDecodeContext(ctx context.Context, opts ...DecodeOption) *Decode {
+	d := NewDecodeWith(opts...)
+	d.ctx = ctx
+	return d
+}
+
+// NewMultiDecodeContext is like NewMultiDecodeWith but the decoding is canceled
+// when ctx is done instead of by the cancel function. Blocked Transform returns
+// promptly with error that matches both ErrUuCancel and ctx.Err() with
+// errors.Is. Close must be called after the decoding to release the goroutine
+// watching ctx.
+func NewMultiDecodeContext(ctx context.Context, opts ...DecodeOption) (*Decode,
+	<-chan io.ReadCloser) {
+	d, cancel, ch := NewMultiDecodeWith(opts...)
+	d.ctx = ctx
+	d.done = make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-d.done:
+		}
+	}()
+	return d, ch
+}
@@ -0,0 +1,73 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/net/context"
+	"golang.org/x/text/transform"
+)
+
+func TestMultiDecodeContext(t *testing.T) {
+	src := make([]byte, tDecBigLen)
+	for i := range src {
+		src[i] = byte(i * 7)
+	}
+	encoded := uuencode.EncodeToString(src)
+	ctx, cancel := context.WithCancel(context.Background())
+	d, ch := uuencode.NewMultiDecodeContext(ctx)
+	go func() {
+		for r := range ch {
+			p := make([]byte, 4)
+			r.Read(p)
+			// Transform is blocked by the unread decoded bytes.
+			cancel()
+		}
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(encoded), d))
+	d.Close()
+	if !errors.Is(err, uuencode.ErrUuCancel) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrUuCancel)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Error("Got: ", err, " Expecting: ", context.Canceled)
+	}
+}
+
+func TestMultiDecodeContextDeadline(t *testing.T) {
+	encoded := uuencode.EncodeToString([]byte("I love you forever."))
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+	d, _ := uuencode.NewMultiDecodeContext(ctx)
+	// nobody reads the chan, so Transform blocks until the deadline.
+	_, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(encoded), d))
+	d.Close()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Got: ", err, " Expecting: ", context.DeadlineExceeded)
+	}
+}
+
+func TestDecodeContext(t *testing.T) {
+	encoded := uuencode.EncodeToString([]byte("I love you forever."))
+	d := uuencode.NewDecodeContext(context.Background())
+	if _, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(encoded), d)); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d = uuencode.NewDecodeContext(ctx)
+	_, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(encoded), d))
+	if !errors.Is(err, context.Canceled) ||
+		!errors.Is(err, uuencode.ErrUuCancel) {
+		t.Error("Got: ", err, " Expecting: ", context.Canceled)
+	}
+}
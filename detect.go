@@ -0,0 +1,162 @@
+package uuencode
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// HasUuencode reports whether r contains uuencoded content. It returns as soon
+// as a plausible begin line followed by a valid body line is found, without
+// decoding the whole content. Read error is reported as false.
+func HasUuencode(r io.Reader) bool {
+	off, _ := IndexUuencode(r)
+	return off >= 0
+}
+
+// HasUuencodeN is like HasUuencode but only inspects the first n bytes of r, so
+// the detection cost is bounded. Content that starts within the first n bytes
+// but whose first body line ends after them is not detected.
+func HasUuencodeN(r io.Reader, n int64) bool {
+	return HasUuencode(io.LimitReader(r, n))
+}
+
+// IndexUuencode returns the byte offset of the begin line of the first
+// plausible uuencoded content in r, or -1 if there is none. A plausible content
+// is a begin line with valid permission bits and file name followed by a valid
+// body line, or by a grave line and end marker line for empty content. The
+// rest of the content is not checked. r is read up to the line after the
+// found begin line.
+func IndexUuencode(r io.Reader) (int64, error) {
+	sc := newLineScanner(r)
+	var begin int64
+	var state int // uuStart, uuBody after begin line, uuEnd after grave line
+	for {
+		line, off, err := sc.next()
+		switch {
+		case line == nil:
+			// too long line can not be part of uuencoded content.
+			state = uuStart
+		case state == uuBody && validBodyLine(line):
+			return begin, nil
+		case state == uuBody && len(line) > 0 && line[0] == uuPadding:
+			state = uuEnd
+		case state == uuEnd && string(line) == uuEndMarker:
+			return begin, nil
+		case plausibleBegin(line):
+			state, begin = uuBody, off
+		default:
+			state = uuStart
+		}
+		if err == io.EOF {
+			return -1, nil
+		} else if err != nil {
+			return -1, err
+		}
+	}
+}
+
+// lineScanner reads lines with bounded memory.
+type lineScanner struct {
+	br  *bufio.Reader
+	off int64
+}
+
+func newLineScanner(r io.Reader) *lineScanner {
+	return &lineScanner{br: bufio.NewReader(r)}
+}
+
+// next returns the next line without end of line characters and its byte
+// offset. The line is only valid until the next call. Too long line is skipped
+// and returned as nil line. The error is io.EOF for the last line, which may be
+// empty.
+func (sc *lineScanner) next() ([]byte, int64, error) {
+	off := sc.off
+	b, err := sc.br.ReadSlice('\n')
+	sc.off += int64(len(b))
+	if off == 0 && err != bufio.ErrBufferFull {
+		// the byte order mark is not part of the first line.
+		b = bytes.TrimPrefix(b, []byte(utf8BOM))
+	}
+	if err == bufio.ErrBufferFull {
+		for err == bufio.ErrBufferFull {
+			b, err = sc.br.ReadSlice('\n')
+			sc.off += int64(len(b))
+		}
+		return nil, off, err
+	}
+	if b == nil {
+		b = []byte{}
+	}
+	return bytes.TrimSuffix(bytes.TrimSuffix(b, []byte{'\n'}), []byte{'\r'}),
+		off, err
+}
+
+// plausibleBegin reports whether line is a begin line with valid permission
+// bits and file name.
+func plausibleBegin(line []byte) bool {
+	as := bytes.SplitN(line, []byte{' '}, 3)
+	if len(as) < 3 || string(as[0]) != uuBeginMarker || len(as[2]) == 0 {
+		return false
+	}
+	_, err := parseMode(string(as[1]))
+	return err == nil
+}
+
+// validBodyLine reports whether line without end of line characters is a
+// valid uuencoded data line carrying at least one byte.
+func validBodyLine(line []byte) bool {
+	if len(line) < 2 || line[0] == uuPadding ||
+		uuDecTable[line[0]] == uuInvalid || (len(line)-1)%4 != 0 {
+		return false
+	}
+	total := (len(line) - 1) / 4 * 3
+	want := int(line[0] - uuOffset)
+	if want == 0 || total < want || total-want > 2 {
+		return false
+	}
+	for _, c := range line[1:] {
+		if uuDecTable[c] == uuInvalid {
+			return false
+		}
+	}
+	return true
+}
+
+// Section is the byte range of one uuencoded content found by FindUuencode,
+// from the start of the begin line through the end of the end marker line.
+type Section struct {
+	Offset, Length int64
+	// Header is the parsed begin line.
+	Header Header
+}
+
+// FindUuencode returns the byte ranges of all uuencoded contents within the
+// first size bytes of r without decoding them, so they can be decoded lazily
+// later, eg: with io.NewSectionReader. A content is a plausible begin line
+// (see IndexUuencode) through the next end marker line. Content without end
+// marker line is not reported. Use bytes.NewReader for []byte input.
+func FindUuencode(r io.ReaderAt, size int64) ([]Section, error) {
+	sc := newLineScanner(io.NewSectionReader(r, 0, size))
+	var ss []Section
+	var cur *Section
+	for {
+		line, off, err := sc.next()
+		switch {
+		case line == nil:
+			// too long line can not be part of uuencoded content.
+			cur = nil
+		case plausibleBegin(line):
+			cur = &Section{Offset: off, Header: parseHeader(line)}
+		case cur != nil && string(line) == uuEndMarker:
+			cur.Length = sc.off - cur.Offset
+			ss = append(ss, *cur)
+			cur = nil
+		}
+		if err == io.EOF {
+			return ss, nil
+		} else if err != nil {
+			return ss, err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+)
+
+var tstIndexUuencodeData = []struct {
+	in  string
+	off int64
+}{
+	{"begin 644 a.txt\n#86)C\n`\nend\n", 0},
+	{"text\r\nbegin 644 a.txt\r\n#86)C\r\n", 6},
+	{"begin 644 empty\n`\nend\n", 0},
+	{"begin 644 empty\n`\n", -1},
+	{"begin 644 a.txt\nnot body\nbegin 600 b.txt\n#86)C\n", 25},
+	{"begin 999 a.txt\n#86)C\n", -1},
+	{"begin 644\n#86)C\n", -1},
+	{"begin 644 a.txt\n#86)\n", -1},
+	{"begin 644 a.txt\n#86)Cx\n", -1},
+	{"begin 644 a.txt\n#8v)C\n", -1},
+	{strings.Repeat("x", 10000) + "\nbegin 644 a.txt\n#86)C\n", 10001},
+	{"\xef\xbb\xbfbegin 644 a.txt\n#86)C\n", 0},
+	{"", -1},
+}
+
+func TestIndexUuencode(t *testing.T) {
+	for i, d := range tstIndexUuencodeData {
+		off, err := uuencode.IndexUuencode(bytes.NewBufferString(d.in))
+		if err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		if off != d.off {
+			t.Error(i, "Got: ", off, " Expecting: ", d.off)
+		}
+	}
+}
+
+// tstEndlessReader returns endless uuencoded body lines.
+type tstEndlessReader struct{}
+
+func (tstEndlessReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = "M86%A86%A86%A86%A86%A86%A86%A86%A86%A86%A86%A86%A86%A86%A86%A\n"[i%62]
+	}
+	return len(b), nil
+}
+
+func TestHasUuencodeEarlyReturn(t *testing.T) {
+	r := bytes.NewBufferString("begin 644 a.txt\n")
+	// HasUuencode must not read the whole endless content.
+	if !uuencode.HasUuencode(io.MultiReader(r, tstEndlessReader{})) {
+		t.Error("Got: false Expecting: true")
+	}
+}
+
+func TestHasUuencodeN(t *testing.T) {
+	const src = "text\nbegin 644 a.txt\n#86)C\n`\nend\n"
+	tsts := []struct {
+		n   int64
+		has bool
+	}{
+		{0, false},
+		{20, false},
+		{26, true}, // body line without the end of line
+		{int64(len(src)), true},
+	}
+	for _, d := range tsts {
+		got := uuencode.HasUuencodeN(bytes.NewBufferString(src), d.n)
+		if got != d.has {
+			t.Error(d.n, "Got: ", got, " Expecting: ", d.has)
+		}
+	}
+	// bounded read of endless input.
+	if uuencode.HasUuencodeN(tstEndlessReader{}, 1<<20) {
+		t.Error("Got: true Expecting: false")
+	}
+}
+
+func TestFindUuencode(t *testing.T) {
+	one := uuencode.EncodeToString([]byte("first content"))
+	two := "begin 600 b.txt\r\n#86)C\r\n`\r\nend"
+	src := "text\n" + one + "begin 644 broken\n#86)C\n" +
+		strings.Repeat("x", 5000) + "\n" + "more text\n" + two
+	ss, err := uuencode.FindUuencode(strings.NewReader(src), int64(len(src)))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	wants := []struct {
+		name, raw string
+	}{
+		{"filename", one},
+		{"b.txt", two},
+	}
+	if len(ss) != len(wants) {
+		t.Fatal("Got: ", ss)
+	}
+	for i, want := range wants {
+		s := ss[i]
+		if s.Header.Name != want.name {
+			t.Error(i, "Got name: ", s.Header.Name, " Expecting: ", want.name)
+		}
+		raw := src[s.Offset : s.Offset+s.Length]
+		if raw != want.raw {
+			t.Errorf("%d Got: %q Expecting: %q", i, raw, want.raw)
+		}
+		got, err := uuencode.DecodeString(raw)
+		if err != nil || len(got) == 0 {
+			t.Error(i, "decode section got: ", err)
+		}
+	}
+	// size limits the scanned bytes.
+	ss, err = uuencode.FindUuencode(strings.NewReader(src), int64(len(one)))
+	if err != nil || len(ss) != 0 {
+		t.Error("Got: ", ss, err)
+	}
+}
@@ -0,0 +1,81 @@
+package uuencode
+
+import (
+	"io"
+	"sync"
+)
+
+// partDigest is the digest of one uuencoded content shared with the consumer
+// of multiple decoding.
+type partDigest struct {
+	mu  sync.Mutex
+	sum []byte
+}
+
+// get returns the digest, nil if the content is not finished yet.
+func (p *partDigest) get() []byte {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sum
+}
+
+// sum adds the decoded bytes b to the digest of the current content.
+func (d *Decode) sum(b []byte) {
+	if d.newHash == nil {
+		return
+	}
+	if d.hash == nil {
+		d.hash = d.newHash()
+	}
+	d.hash.Write(b)
+}
+
+// finishSum finishes the digest of the current content at its end marker line.
+func (d *Decode) finishSum() {
+	if d.newHash == nil {
+		return
+	}
+	if d.hash == nil {
+		// empty content.
+		d.hash = d.newHash()
+	}
+	sum := d.hash.Sum(nil)
+	d.hash = nil
+	d.Lock()
+	d.digest = sum
+	d.Unlock()
+	if d.partSum != nil {
+		d.partSum.mu.Lock()
+		d.partSum.sum = sum
+		d.partSum.mu.Unlock()
+	}
+}
+
+// Digest returns the digest of WithDigest of the last uuencoded content whose
+// end marker line is decoded. It is nil without WithDigest or before the first
+// end marker line. It is safe to call while another goroutine is decoding.
+func (d *Decode) Digest() []byte {
+	d.Lock()
+	defer d.Unlock()
+	return d.digest
+}
+
+// Digest returns the digest of WithDigest of the decoded bytes of p. It is
+// only available after the ReadCloser returns io.EOF, it is nil before that or
+// without WithDigest.
+func (p MultiPart) Digest() []byte {
+	return p.sum.get()
+}
+
+// PartDigest is like MultiPart.Digest but for r received from the chan of
+// NewMultiDecode, NewMultiDecodeWith or NewMultiDecodeContext. ok is false if
+// r is not received from such chan.
+func PartDigest(r io.Reader) (sum []byte, ok bool) {
+	if hr, ok := r.(*headerReader); ok {
+		return hr.sum.get(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,124 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func sha256Sum(s string) []byte {
+	sum := sha256.Sum256([]byte(s))
+	return sum[:]
+}
+
+func crc32Sum(s string) []byte {
+	h := crc32.NewIEEE()
+	io.WriteString(h, s)
+	return h.Sum(nil)
+}
+
+func TestDecodeDigest(t *testing.T) {
+	tsts := []struct {
+		d    *uuencode.Decode
+		in   string
+		want []byte
+	}{
+		{
+			d:    uuencode.NewDecodeWith(uuencode.WithDigest(sha256.New)),
+			in:   "text\nbegin 644 a\n#0V%T\n`\nend\nmore\n",
+			want: sha256Sum("Cat"),
+		},
+		{
+			d: uuencode.NewDecodeWith(uuencode.WithDigest(sha256.New),
+				uuencode.WithTextEOL("\r\n")),
+			in:   "begin 644 a\n$80IB\"@``\n`\nend\n",
+			want: sha256Sum("a\r\nb\r\n"),
+		},
+		{
+			d:    uuencode.NewDecodeWith(uuencode.WithDigest(sha256.New)),
+			in:   "begin 644 a\n`\nend\n",
+			want: sha256Sum(""),
+		},
+		{
+			d:  uuencode.NewDecode(),
+			in: "begin 644 a\n#0V%T\n`\nend\n",
+		},
+	}
+	for i, tst := range tsts {
+		if _, _, err := transform.String(tst.d, tst.in); err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		if diff := pretty.Compare(tst.d.Digest(), tst.want); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+		tst.d.Reset()
+		if sum := tst.d.Digest(); sum != nil {
+			t.Errorf("%d Got digest after Reset: %x", i, sum)
+		}
+	}
+}
+
+func TestMultiDecodeDigest(t *testing.T) {
+	abc := bytes.Repeat([]byte("abc"), 1000)
+	in := "begin 644 a\n#0V%T\n`\nend\ntext\n" +
+		uuencode.EncodeToString(abc)
+	wants := [][]byte{sha256Sum("Cat"), sha256Sum(string(abc))}
+	d, _, ch := uuencode.NewMultiDecodeParts(uuencode.WithDigest(sha256.New))
+	var got [][]byte
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for p := range ch {
+			io.Copy(ioutil.Discard, p)
+			got = append(got, p.Digest())
+		}
+	}()
+	if _, err := io.Copy(ioutil.Discard, transform.NewReader(
+		bytes.NewBufferString(in), d)); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d.Close()
+	wg.Wait()
+	if diff := pretty.Compare(got, wants); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// the plain chan carries the digest too.
+	d, _, rch := uuencode.NewMultiDecodeWith(uuencode.WithDigest(
+		func() hash.Hash { return crc32.NewIEEE() }))
+	got = nil
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for r := range rch {
+			io.Copy(ioutil.Discard, r)
+			sum, ok := uuencode.PartDigest(r)
+			if !ok {
+				t.Error("Expecting digest of the chan reader")
+			}
+			got = append(got, sum)
+		}
+	}()
+	if _, err := io.Copy(ioutil.Discard, transform.NewReader(
+		bytes.NewBufferString(in), d)); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d.Close()
+	wg.Wait()
+	wants = [][]byte{crc32Sum("Cat"), crc32Sum(string(abc))}
+	if diff := pretty.Compare(got, wants); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	if _, ok := uuencode.PartDigest(bytes.NewBufferString("")); ok {
+		t.Error("Got digest of non-chan reader")
+	}
+}
@@ -0,0 +1,168 @@
+package uuencode
+
+import (
+	"bytes"
+)
+
+// Encoding is the uuencoded body lines encoding, without the begin line and the
+// end marker lines, like encoding/base64.Encoding. Encoded data is lines of up
+// to 45 bytes, each starts with its length character and ends with the end of
+// line characters.
+type Encoding struct {
+	useGrave bool
+	eol      string
+	alpha    *Alphabet
+	strict   bool
+}
+
+// StdEncoding is the standard uuencode encoding using grave as padding and \n
+// as end of line, the same line format as Uue.NewEncoder.
+var StdEncoding = NewEncoding(nil)
+
+// NewEncoding returns Encoding using the characters set a, eg: XXAlphabet. nil
+// a uses the standard uuencode characters.
+func NewEncoding(a *Alphabet) *Encoding {
+	return &Encoding{useGrave: true, eol: "\n", alpha: a}
+}
+
+// WithPadding returns a copy of enc using padding, either grave '`' or space
+// ' ', as the padding and zero character. It panics for other padding. It has
+// no effect on custom characters set.
+func (enc Encoding) WithPadding(padding rune) *Encoding {
+	switch padding {
+	case uuPadding:
+		enc.useGrave = true
+	case uuOffset:
+		enc.useGrave = false
+	default:
+		panic("uuencode: invalid padding")
+	}
+	return &enc
+}
+
+// WithEOL returns a copy of enc using eol, eg: \r\n, as the end of line.
+func (enc Encoding) WithEOL(eol string) *Encoding {
+	enc.eol = eol
+	return &enc
+}
+
+// Strict returns a copy of enc that decoding rejects data line that is not
+// exactly the encoding of its bytes, eg: non-zero padding bits, the other zero
+// character or different end of line.
+func (enc Encoding) Strict() *Encoding {
+	enc.strict = true
+	return &enc
+}
+
+// EncodedLen returns the length in bytes of the encoding of n source bytes.
+func (enc *Encoding) EncodedLen(n int) int {
+	return encodedLen(n, enc.eol)
+}
+
+// encodedLen returns the length in bytes of the data lines of n source bytes
+// ended by eol.
+func encodedLen(n int, eol string) int {
+	l := n / maxSingleLine * (maxEncLine + len(eol))
+	if r := n % maxSingleLine; r > 0 {
+		l += 1 + (r+2)/3*4 + len(eol)
+	}
+	return l
+}
+
+// DecodedLen returns the maximum length in bytes of the decoded data
+// corresponding to n bytes of encoded data.
+func (enc *Encoding) DecodedLen(n int) int {
+	return n / 4 * 3
+}
+
+// Encode encodes src into EncodedLen(len(src)) bytes of dst.
+func (enc *Encoding) Encode(dst, src []byte) {
+	u := uuBodyEnc{useGrave: enc.useGrave, eol: enc.eol, bodyOnly: true,
+		alpha: enc.alpha}
+	u.Transform(dst, src, true)
+}
+
+// EncodeToString returns the encoding of src.
+func (enc *Encoding) EncodeToString(src []byte) string {
+	b := make([]byte, enc.EncodedLen(len(src)))
+	enc.Encode(b, src)
+	return string(b)
+}
+
+// Decode decodes src into at most DecodedLen(len(src)) bytes of dst and
+// returns the number of bytes written. A blank or grave line ends the data,
+// only white spaces may follow it. The error is *DecodeError.
+func (enc *Encoding) Decode(dst, src []byte) (int, error) {
+	u := uuBodyDec{bodyOnly: true, alpha: enc.alpha}
+	n, m, err := u.Transform(dst, src, true)
+	if err == errFoundEOF {
+		if len(bytes.TrimSpace(src[m:])) > 0 {
+			err = badLine(firstLine(src[m:]), ErrBadUUDec,
+				"data after the end of body")
+		} else {
+			err = nil
+		}
+	}
+	if err == nil && enc.strict {
+		m, err = enc.strictCheck(src)
+	}
+	if err != nil {
+		return n, &DecodeError{Line: bytes.Count(src[:m], []byte{'\n'}) + 1,
+			Offset: int64(m), Err: err}
+	}
+	return n, nil
+}
+
+// strictCheck returns the offset and error of the first data line that is not
+// exactly the encoding of its bytes.
+func (enc *Encoding) strictCheck(src []byte) (int, error) {
+	// the longest line has 63 bytes length character and 2 padding bytes.
+	var dec [maxUuDecLine + 2]byte
+	var canonical []byte
+	var off int
+	for off < len(src) {
+		line := src[off:]
+		adv := len(line)
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line, adv = line[:i+1], i+1
+		}
+		data := bytes.TrimRight(line, "\r\n")
+		if len(data) == 0 || data[0] == uuPadding ||
+			enc.alpha != nil && data[0] == enc.alpha.enc[0] {
+			// the end of the data.
+			return off, nil
+		}
+		u := uuBodyDec{bodyOnly: true, alpha: enc.alpha}
+		n, _, _ := u.Transform(dec[:], data, true)
+		if l := enc.EncodedLen(n); cap(canonical) < l {
+			canonical = make([]byte, l)
+		} else {
+			canonical = canonical[:l]
+		}
+		enc.Encode(canonical, dec[:n])
+		if len(line) == len(data) {
+			// the last line without end of line characters.
+			canonical = canonical[:len(canonical)-len(enc.eol)]
+		}
+		if !bytes.Equal(line, canonical) {
+			return off, badLine(data, ErrBadUUDec, "not canonical")
+		}
+		off += adv
+	}
+	return off, nil
+}
+
+// DecodeString returns the bytes represented by the encoded s.
+func (enc *Encoding) DecodeString(s string) ([]byte, error) {
+	b := make([]byte, enc.DecodedLen(len(s)))
+	n, err := enc.Decode(b, []byte(s))
+	return b[:n], err
+}
+
+// firstLine returns the first line of b without the end of line characters.
+func firstLine(b []byte) []byte {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		b = b[:i]
+	}
+	return bytes.TrimSuffix(b, []byte{'\r'})
+}
@@ -0,0 +1,97 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	encs := []*uuencode.Encoding{
+		uuencode.StdEncoding,
+		uuencode.StdEncoding.WithPadding(' '),
+		uuencode.StdEncoding.WithEOL("\r\n"),
+		uuencode.NewEncoding(uuencode.XXAlphabet),
+		uuencode.StdEncoding.Strict(),
+	}
+	for i, enc := range encs {
+		for _, n := range []int{0, 1, 2, 3, 44, 45, 46, 90, 1000} {
+			src := make([]byte, n)
+			for j := range src {
+				src[j] = byte(j * 7)
+			}
+			s := enc.EncodeToString(src)
+			if len(s) != enc.EncodedLen(n) {
+				t.Errorf("%d %d Got length %d want %d", i, n, len(s),
+					enc.EncodedLen(n))
+			}
+			b, err := enc.DecodeString(s)
+			if err != nil || !bytes.Equal(b, src) {
+				t.Errorf("%d %d Got: %v", i, n, err)
+			}
+			if enc.DecodedLen(len(s)) < n {
+				t.Errorf("%d %d Got decoded length %d", i, n,
+					enc.DecodedLen(len(s)))
+			}
+		}
+	}
+}
+
+func TestEncodingBodyEncode(t *testing.T) {
+	src := bytes.Repeat([]byte("abcdefg"), 20)
+	want, _, err := transform.Bytes(uuencode.NewBodyEncode(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := uuencode.StdEncoding.EncodeToString(src); got != string(want) {
+		t.Errorf("Got: %q\nWant: %q", got, want)
+	}
+	r := transform.NewReader(strings.NewReader(string(want)),
+		uuencode.NewBodyDecode())
+	if b, err := ioutil.ReadAll(r); err != nil || !bytes.Equal(b, src) {
+		t.Error("Got: ", err)
+	}
+}
+
+func TestEncodingDecodeFail(t *testing.T) {
+	tsts := []struct {
+		enc  *uuencode.Encoding
+		in   string
+		line int
+	}{
+		{uuencode.StdEncoding, "#86)C\n#86)\n", 2},
+		{uuencode.StdEncoding, "#86)C\n`\n#86)C\n", 3},
+		{uuencode.StdEncoding.Strict(), "#86)C\n\"86)C\n", 2},
+		{uuencode.StdEncoding.Strict(), "!80``\n!80  \n", 2},
+		{uuencode.StdEncoding.Strict(), "#86)C\r\n", 1},
+	}
+	for i, tst := range tsts {
+		_, err := tst.enc.DecodeString(tst.in)
+		var de *uuencode.DecodeError
+		if !errors.As(err, &de) || !errors.Is(err, uuencode.ErrBadUUDec) ||
+			de.Line != tst.line {
+			t.Errorf("%d Got: %v", i, err)
+		}
+	}
+	// not strict accepts the same input.
+	for _, in := range []string{"\"86)C\n",
+		"!80  \n", "#86)C\r\n", "#86)C\n`\n"} {
+		if _, err := uuencode.StdEncoding.DecodeString(in); err != nil {
+			t.Errorf("%q Got: %v", in, err)
+		}
+	}
+}
+
+func TestEncodingWithPaddingPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic")
+		}
+	}()
+	uuencode.StdEncoding.WithPadding('=')
+}
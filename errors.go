@@ -0,0 +1,224 @@
+package uuencode
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// The distinct kinds of decoding failure. They all wrap ErrBadUUDec, so
+// errors.Is(err, ErrBadUUDec) still holds for any of them.
+var (
+	// ErrBadCount indicates an uuencoded body line starts with an invalid
+	// length character.
+	ErrBadCount = fmt.Errorf("uuencode: invalid length character: %w",
+		ErrBadUUDec)
+	// ErrBadChar indicates an uuencoded body line has a character outside of
+	// the uuencode characters set.
+	ErrBadChar = fmt.Errorf("uuencode: invalid character: %w", ErrBadUUDec)
+	// ErrShortBody indicates an uuencoded body line has fewer characters than
+	// its length character requires.
+	ErrShortBody = fmt.Errorf("uuencode: line shorter than length: %w",
+		ErrBadUUDec)
+	// ErrMissingEnd indicates an uuencoded content without the end marker
+	// line.
+	ErrMissingEnd = fmt.Errorf("uuencode: missing end marker: %w",
+		ErrBadUUDec)
+	// ErrBadBegin indicates a malformed begin line.
+	ErrBadBegin = fmt.Errorf("uuencode: malformed begin line: %w",
+		ErrBadUUDec)
+)
+
+// BadLineError indicates an uuencoded body line that does not follow uuencode
+// format. It wraps Err.
+type BadLineError struct {
+	// Line is the failing line without the end of line characters.
+	Line string
+	// Reason describes which uuencode rule the line violates.
+	Reason string
+	// Err is the kind of the violated rule, eg: ErrBadCount or ErrBadChar. It
+	// is ErrBadUUDec for other rules.
+	Err error
+}
+
+// badLine returns *BadLineError of line violating rule kind err with reason.
+func badLine(line []byte, err error, reason string) error {
+	return &BadLineError{Line: string(line), Reason: reason, Err: err}
+}
+
+func (e *BadLineError) Error() string {
+	return fmt.Sprintf("uuencode: bad uuencode line %q: %s", e.Line, e.Reason)
+}
+
+// Unwrap returns the kind of the violated rule.
+func (e *BadLineError) Unwrap() error {
+	if e.Err == nil {
+		return ErrBadUUDec
+	}
+	return e.Err
+}
+
+// MissingEndError indicates an uuencoded content without the end marker line.
+// It wraps ErrMissingEnd.
+type MissingEndError struct {
+	// Line is the line found in place of the end marker line. It is empty if
+	// input ends before the end marker line.
+	Line string
+}
+
+func (e *MissingEndError) Error() string {
+	if e.Line == "" {
+		return "uuencode: missing end marker"
+	}
+	return fmt.Sprintf("uuencode: missing end marker, found %q", e.Line)
+}
+
+// Unwrap returns ErrMissingEnd.
+func (e *MissingEndError) Unwrap() error {
+	return ErrMissingEnd
+}
+
+// ChecksumError indicates the decoded content does not match the checksum
+// carried by the input, eg: the "crc32 1a2b3c4d" trailer line after the end
+// marker line. It wraps ErrBadUUDec.
+type ChecksumError struct {
+	// Kind is the kind of checksum, eg: "crc32".
+	Kind string
+	// Want is the value carried by the input and Got is the value computed
+	// from the decoded content.
+	Want, Got uint64
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("uuencode: %s mismatch, want %d got %d", e.Kind, e.Want,
+		e.Got)
+}
+
+// Unwrap returns ErrBadUUDec.
+func (e *ChecksumError) Unwrap() error {
+	return ErrBadUUDec
+}
+
+// HeaderError indicates a malformed begin line. It wraps the cause error, eg:
+// ErrBadLen or ErrBadMode, and errors.Is reports it is ErrBadBegin and
+// ErrBadUUDec.
+type HeaderError struct {
+	// Raw is the begin line, it may be truncated for too long line.
+	Raw string
+	// Err is the cause of failure.
+	Err error
+}
+
+func (e *HeaderError) Error() string {
+	return fmt.Sprintf("uuencode: bad begin line %q: %v", e.Raw, e.Err)
+}
+
+// Unwrap returns the cause of failure.
+func (e *HeaderError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrBadBegin or ErrBadUUDec.
+func (e *HeaderError) Is(target error) bool {
+	return target == ErrBadBegin || target == ErrBadUUDec
+}
+
+// DecodeError records the position of the input where decoding fails. It
+// wraps the cause error, eg: ErrBadUUDec, so errors.Is still works on it.
+type DecodeError struct {
+	// Line is the line number (starts from 1) of the failing input line.
+	Line int
+	// Offset is the byte offset of the start of the failing input line.
+	Offset int64
+	// Part is the index (starts from 0) of the uuencoded content that fails.
+	// If failure happens outside uuencoded content, it is the index of the
+	// next uuencoded content.
+	Part int
+	// Err is the cause of failure.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%v at line %d (offset %d, part %d)", e.Err, e.Line,
+		e.Offset, e.Part)
+}
+
+// Unwrap returns the cause of failure.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// position returns the line number, byte offset and part index of the input
+// line that starts right after consumed. consumed is the part of current source
+// bytes that has been processed.
+func (d *Decode) position(consumed []byte) (int, int64, int) {
+	part := d.parts
+	if d.state != uuStart {
+		part--
+	}
+	return d.line + bytes.Count(consumed, []byte{'\n'}) + 1,
+		d.offset + int64(len(consumed)), part
+}
+
+// posError wraps err as *DecodeError. consumed is the part of current source
+// bytes that has been processed before the failure.
+func (d *Decode) posError(consumed []byte, err error) error {
+	e := &DecodeError{Err: err}
+	e.Line, e.Offset, e.Part = d.position(consumed)
+	return e
+}
+
+// Warning records a recoverable oddity found during decoding, eg: a junk line
+// skipped in lenient mode.
+type Warning struct {
+	// Line, Offset and Part has the same meaning as in DecodeError.
+	Line   int
+	Offset int64
+	Part   int
+	// Reason describes the oddity.
+	Reason string
+	// Text is the input line that causes the warning.
+	Text string
+}
+
+// maxWarnings is the maximum number of warnings kept for Warnings.
+const maxWarnings = 1024
+
+// OnWarning sets f to be called for every warning found during decoding. f runs
+// in the goroutine calling Transform.
+func (d *Decode) OnWarning(f func(Warning)) {
+	d.onWarning = f
+}
+
+// Warnings returns the warnings found since the last Reset in input order, eg:
+// lines ending with CR only, junk lines skipped in lenient mode and re-padded
+// lines. Only the first 1024 warnings are kept, use OnWarning to see all of
+// them. It is safe to call while another goroutine is decoding.
+func (d *Decode) Warnings() []Warning {
+	d.Lock()
+	defer d.Unlock()
+	return append([]Warning(nil), d.warnings...)
+}
+
+// warning reports the warning of input line that starts right after consumed.
+func (d *Decode) warning(consumed []byte, reason, text string) {
+	w := Warning{Reason: reason, Text: text}
+	w.Line, w.Offset, w.Part = d.position(consumed)
+	d.Lock()
+	if len(d.warnings) < maxWarnings {
+		d.warnings = append(d.warnings, w)
+	}
+	d.Unlock()
+	if d.onWarning != nil {
+		d.onWarning(w)
+	}
+}
+
+// checkCR reports the warning if line without LF has CR in the middle, ie:
+// some lines end with CR only. Such input is decoded as a single line, so the
+// begin line hidden in it is not found. consumed is the part of current
+// source bytes before line.
+func (d *Decode) checkCR(consumed, line []byte) {
+	if bytes.IndexByte(bytes.TrimSuffix(line, []byte{'\r'}), '\r') >= 0 {
+		d.warning(consumed, "CR only line ending", string(line))
+	}
+}
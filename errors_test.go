@@ -0,0 +1,236 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+var tstDecodeErrorData = []struct {
+	in  string
+	err uuencode.DecodeError
+}{
+	{
+		in: "text\nbegin 644 file.txt\n#0V%T\n#0V%\n`\nend\n",
+		err: uuencode.DecodeError{Line: 4, Offset: 30, Part: 0,
+			Err: &uuencode.BadLineError{Line: "#0V%",
+				Reason: "length is not multiple of 4",
+				Err:    uuencode.ErrBadUUDec}},
+	},
+	{
+		in: "begin 644 file.txt\n#0V%T\n`\nnot end\n",
+		err: uuencode.DecodeError{Line: 4, Offset: 27, Part: 0,
+			Err: &uuencode.MissingEndError{Line: "not end"}},
+	},
+	{
+		in: "begin 644 one.txt\n#0V%T\n`\nend\nbegin 644 two.txt\n#0V%T\n" +
+			"a0V%T\n`\nend\n",
+		err: uuencode.DecodeError{Line: 7, Offset: 54, Part: 1,
+			Err: &uuencode.BadLineError{Line: "a0V%T",
+				Reason: "invalid length character",
+				Err:    uuencode.ErrBadCount}},
+	},
+	{
+		in: "begin 644 file.txt\n#0V%T\n#0v%T\n`\nend\n",
+		err: uuencode.DecodeError{Line: 3, Offset: 25, Part: 0,
+			Err: &uuencode.BadLineError{Line: "#0v%T",
+				Reason: "invalid character", Err: uuencode.ErrBadChar}},
+	},
+}
+
+func TestDecodeError(t *testing.T) {
+	for _, d := range tstDecodeErrorData {
+		dec, _, ch := uuencode.NewMultiDecode()
+		go func() {
+			for r := range ch {
+				io.Copy(ioutil.Discard, r)
+			}
+		}()
+		_, err := ioutil.ReadAll(transform.NewReader(
+			bytes.NewBufferString(d.in), dec))
+		dec.Close()
+		if !errors.Is(err, uuencode.ErrBadUUDec) {
+			t.Fatal("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
+		}
+		var derr *uuencode.DecodeError
+		if !errors.As(err, &derr) {
+			t.Fatalf("Expecting *uuencode.DecodeError but got %T", err)
+		}
+		if diff := pretty.Compare(*derr, d.err); diff != "" {
+			t.Errorf("Diff: %s", diff)
+		}
+	}
+}
+
+func TestDecodeErrorSentinel(t *testing.T) {
+	tsts := []struct {
+		in   string
+		kind error
+	}{
+		{"begin 644 a\na0V%T\n`\nend\n", uuencode.ErrBadCount},
+		{"begin 644 a\n#0v%T\n`\nend\n", uuencode.ErrBadChar},
+		{"begin 644 a\n'0V%T\n`\nend\n", uuencode.ErrShortBody},
+		{"begin 644 a\n#0V%T\n`\nnot end\n", uuencode.ErrMissingEnd},
+		{"begin 644 a\n#0V%T\n", uuencode.ErrMissingEnd},
+		{"begin 644 a" + strings.Repeat("x", 5000), uuencode.ErrBadBegin},
+	}
+	kinds := []error{uuencode.ErrBadCount, uuencode.ErrBadChar,
+		uuencode.ErrShortBody, uuencode.ErrMissingEnd, uuencode.ErrBadBegin}
+	for i, tst := range tsts {
+		_, err := ioutil.ReadAll(transform.NewReader(
+			bytes.NewBufferString(tst.in), uuencode.NewDecode()))
+		if !errors.Is(err, tst.kind) {
+			t.Errorf("%d Got: %v Expecting: %v", i, err, tst.kind)
+		}
+		for _, kind := range kinds {
+			if kind != tst.kind && errors.Is(err, kind) {
+				t.Errorf("%d Got: %v is also %v", i, err, kind)
+			}
+		}
+		if !errors.Is(err, uuencode.ErrBadUUDec) {
+			t.Errorf("%d Got: %v Expecting: %v", i, err,
+				uuencode.ErrBadUUDec)
+		}
+	}
+}
+
+func TestDecodeErrorSingle(t *testing.T) {
+	in := "begin 644 file.txt\n#0V%T\n`\nend\n"
+	dec := uuencode.NewDecode()
+	_, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(in[:len(in)-6]), dec))
+	var derr *uuencode.DecodeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Expecting *uuencode.DecodeError but got %T", err)
+	}
+	want := uuencode.DecodeError{Line: 3, Offset: 25, Part: 0,
+		Err: &uuencode.MissingEndError{}}
+	if diff := pretty.Compare(*derr, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+var tstErrorKindData = []struct {
+	in     string
+	target interface{}
+	is     error
+}{
+	{
+		in:     "begin 644 file.txt\n#0V%\n`\nend\n",
+		target: new(*uuencode.BadLineError),
+		is:     uuencode.ErrBadUUDec,
+	},
+	{
+		in:     "begin 644 file.txt\n\n`\nend\n",
+		target: new(*uuencode.BadLineError),
+		is:     uuencode.ErrBadUUDec,
+	},
+	{
+		in:     "begin 644 file.txt\n#0V%T\n",
+		target: new(*uuencode.MissingEndError),
+		is:     uuencode.ErrBadUUDec,
+	},
+	{
+		in:     "begin 644 " + string(bytes.Repeat([]byte("a"), 5000)) + "\n",
+		target: new(*uuencode.HeaderError),
+		is:     uuencode.ErrBadLen,
+	},
+}
+
+func TestDecodeErrorKind(t *testing.T) {
+	for i, d := range tstErrorKindData {
+		_, err := ioutil.ReadAll(uuencode.NewReader(bytes.NewBufferString(d.in)))
+		if !errors.As(err, d.target) {
+			t.Errorf("%d: Expecting %T but got %v", i, d.target, err)
+		}
+		if !errors.Is(err, d.is) {
+			t.Errorf("%d: Got: %v Expecting: %v", i, err, d.is)
+		}
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	line := &uuencode.BadLineError{Line: "a0V%T", Err: uuencode.ErrBadCount}
+	tsts := []struct {
+		err  error
+		want []error
+	}{
+		{line, []error{uuencode.ErrBadCount, uuencode.ErrBadUUDec}},
+		{&uuencode.BadLineError{}, []error{uuencode.ErrBadUUDec}},
+		{&uuencode.MissingEndError{}, []error{uuencode.ErrMissingEnd,
+			uuencode.ErrBadUUDec}},
+		{&uuencode.ChecksumError{Kind: "crc32"},
+			[]error{uuencode.ErrBadUUDec}},
+		{&uuencode.HeaderError{Err: uuencode.ErrBadMode},
+			[]error{uuencode.ErrBadMode, uuencode.ErrBadBegin,
+				uuencode.ErrBadUUDec}},
+		{&uuencode.HeaderError{Err: uuencode.ErrBadLen},
+			[]error{uuencode.ErrBadLen, uuencode.ErrBadBegin,
+				uuencode.ErrBadUUDec}},
+		{&uuencode.DecodeError{Err: line}, []error{uuencode.ErrBadCount,
+			uuencode.ErrBadUUDec}},
+		{&uuencode.DecodeError{Err: &uuencode.HeaderError{
+			Err: uuencode.ErrBadLen}}, []error{uuencode.ErrBadLen,
+			uuencode.ErrBadBegin, uuencode.ErrBadUUDec}},
+	}
+	targets := []error{uuencode.ErrBadUUDec, uuencode.ErrBadCount,
+		uuencode.ErrBadChar, uuencode.ErrShortBody, uuencode.ErrMissingEnd,
+		uuencode.ErrBadBegin, uuencode.ErrBadLen, uuencode.ErrBadMode}
+	for i, tst := range tsts {
+		for _, target := range targets {
+			want := false
+			for _, w := range tst.want {
+				want = want || w == target
+			}
+			if errors.Is(tst.err, target) != want {
+				t.Errorf("%d Got errors.Is(%v, %v) = %t", i, tst.err, target,
+					!want)
+			}
+		}
+	}
+}
+
+func TestDecodeWarnings(t *testing.T) {
+	in := "one\rtwo\r\nbegin 644 a\n#0V%T\n\n#0V%T\n`\nend\n"
+	d := uuencode.NewDecodeWith(uuencode.WithLenient(true))
+	out, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in),
+		d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(out), "one\rtwo\r\nCatCat"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	want := []uuencode.Warning{
+		{Line: 1, Offset: 0, Reason: "CR only line ending",
+			Text: "one\rtwo\r"},
+		{Line: 4, Offset: 27, Reason: "empty line"},
+	}
+	if diff := pretty.Compare(d.Warnings(), want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	d.Reset()
+	if w := d.Warnings(); len(w) != 0 {
+		t.Error("Got warnings after Reset:", w)
+	}
+}
+
+func TestDecodeWarningsLimit(t *testing.T) {
+	in := "begin 644 a\n" + strings.Repeat("\n", 2000) + "#0V%T\n`\nend\n"
+	var n int
+	d := uuencode.NewDecodeWith(uuencode.WithLenient(true))
+	d.OnWarning(func(uuencode.Warning) { n++ })
+	if _, _, err := transform.String(d, in); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if n != 2000 || len(d.Warnings()) != 1024 {
+		t.Error("Got", n, "warnings and kept", len(d.Warnings()))
+	}
+}
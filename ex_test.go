@@ -6,8 +6,10 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"sync"
 
 	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/transform"
 )
 
@@ -34,3 +36,35 @@ func ExampleDecode() {
 	// Bytes1: [18 0 16 16 17 144 0 0]
 	// Bytes2: I love you forever.
 }
+
+func ExampleDecode_Clone() {
+	// configure once and hand out clones, eg: per HTTP request.
+	base := uuencode.NewDecodeWith(uuencode.WithLenient(true))
+	pool := sync.Pool{New: func() interface{} { return base.Clone() }}
+	d := pool.Get().(*uuencode.Decode)
+	d.Reset()
+	r := bytes.NewBufferString("begin 664 uutest2.txt\n322!L;W9E('EO=2!F;W)E=F5R+@``\n`\nend\n")
+	output, err := ioutil.ReadAll(transform.NewReader(r, d))
+	pool.Put(d)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(output))
+	// Output:
+	// I love you forever.
+}
+
+func ExampleWithConcat() {
+	in := "one\nbegin 644 a.txt\n#0V%T\n`\nend\ntwo\n" +
+		"begin 644 b.txt\n#1&]G\n`\nend\n"
+	dec := &encoding.Decoder{
+		Transformer: uuencode.NewDecodeWith(uuencode.WithConcat(true)),
+	}
+	output, err := dec.String(in)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%q\n", output)
+	// Output:
+	// "one\nCattwo\nDog"
+}
@@ -0,0 +1,96 @@
+package uuencode
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is one uuencoded content as a value, for programs that think in files
+// rather than transformers.
+type File struct {
+	// Name is the file name and Mode is the permission bits of the begin line.
+	Name string
+	Mode fs.FileMode
+	// Body is the file content. For File returned by DecodeFile, it reads the
+	// decoded bytes from the underlying reader and returns io.EOF after the
+	// end marker line.
+	Body io.Reader
+}
+
+// Encode writes the uuencoded f into w. The begin line file name and
+// permission bits are taken from f, opts are applied after them so WithFilename
+// or WithMode still override. Nil Body is encoded as an empty content.
+func (f File) Encode(w io.Writer, opts ...EncodeOption) error {
+	e := NewEncodeWith(append([]EncodeOption{WithFilename(f.Name),
+		WithMode(f.Mode)}, opts...)...)
+	ew := NewWriter(w, e)
+	if f.Body != nil {
+		if _, err := io.Copy(ew, f.Body); err != nil {
+			return err
+		}
+	}
+	return ew.Close()
+}
+
+// DecodeFile returns the first uuencoded content of r as File. Any bytes before
+// it are skipped. The Body is decoded while it is read, so r must not be used
+// until Body returns io.EOF. It returns io.EOF if r has no uuencoded content.
+func DecodeFile(r io.Reader) (File, error) {
+	p, err := NewPartReader(r).NextPart()
+	if err != nil {
+		return File{}, err
+	}
+	return File{Name: p.Header.Name, Mode: p.Header.Mode, Body: p}, nil
+}
+
+// EncodeFile writes the uuencoded content of f into w. The begin line file
+// name and permission bits are taken from f.Stat, opts are applied after them
+// so WithFilename or WithMode still override. f is read from its current
+// offset until EOF and is not closed.
+func EncodeFile(w io.Writer, f *os.File, opts ...EncodeOption) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return File{Name: fi.Name(), Mode: fi.Mode(), Body: f}.Encode(w, opts...)
+}
+
+// WriteFile writes data uuencoded into the file path, creating it with
+// permission bits mode if it does not exist. The begin line has mode and the
+// base name of path without the ".uu" extension, eg: "dir/a.txt.uu" outputs
+// "begin 644 a.txt".
+func WriteFile(path string, data []byte, mode fs.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
+		mode.Perm())
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".uu")
+	err = File{Name: name, Mode: mode, Body: bytes.NewReader(data)}.Encode(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ReadFile returns the decoded bytes and the begin line of the first uuencoded
+// content of the file path. Any bytes outside it are ignored. It returns io.EOF
+// if the file has no uuencoded content.
+func ReadFile(path string) ([]byte, Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, Header{}, err
+	}
+	defer f.Close()
+	p, err := NewPartReader(f).NextPart()
+	if err != nil {
+		return nil, Header{}, err
+	}
+	data, err := ioutil.ReadAll(p)
+	return data, p.Header, err
+}
@@ -0,0 +1,141 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+)
+
+func TestEncodeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uuencode")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "cat.txt")
+	if err = ioutil.WriteFile(name, []byte("Cat"), 0600); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	// the file mode may be changed by umask.
+	if err = os.Chmod(name, 0600); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	tsts := []struct {
+		opts []uuencode.EncodeOption
+		want string
+	}{
+		{want: "begin 600 cat.txt\n#0V%T\n`\nend\n"},
+		{
+			opts: []uuencode.EncodeOption{uuencode.WithFilename("dog.txt"),
+				uuencode.WithEOL("\r\n")},
+			want: "begin 600 dog.txt\r\n#0V%T\r\n`\r\nend\r\n",
+		},
+	}
+	for i, tst := range tsts {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		var b bytes.Buffer
+		err = uuencode.EncodeFile(&b, f, tst.opts...)
+		f.Close()
+		if err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		if diff := pretty.Compare(b.String(), tst.want); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+	}
+	// closed file fails at Stat.
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	f.Close()
+	if err = uuencode.EncodeFile(new(bytes.Buffer), f); err == nil {
+		t.Error("Expecting error but got nil")
+	}
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	tsts := []uuencode.File{
+		{Name: "cat.txt", Mode: 0600, Body: bytes.NewBufferString("Cat")},
+		{Name: "my file.txt", Mode: os.ModeSetuid | 0755,
+			Body: bytes.NewReader(bytes.Repeat([]byte("abc"), 100))},
+		{Name: "empty", Mode: 0644},
+	}
+	for i, tst := range tsts {
+		var body []byte
+		if tst.Body != nil {
+			body, _ = ioutil.ReadAll(tst.Body)
+			tst.Body = bytes.NewReader(body)
+		}
+		var b bytes.Buffer
+		b.WriteString("junk\n")
+		if err := tst.Encode(&b); err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		f, err := uuencode.DecodeFile(&b)
+		if err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		got, err := ioutil.ReadAll(f.Body)
+		if err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		// the encoded begin line only has the permission bits.
+		want := uuencode.File{Name: tst.Name, Mode: tst.Mode.Perm()}
+		f.Body = nil
+		if diff := pretty.Compare(f, want); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+		if diff := pretty.Compare(string(got), string(body)); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+	}
+	if _, err := uuencode.DecodeFile(bytes.NewBufferString("text\n")); err !=
+		io.EOF {
+		t.Error("Got: ", err, " Expecting: ", io.EOF)
+	}
+}
+
+func TestWriteReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uuencode")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "cat.txt.uu")
+	if err = uuencode.WriteFile(name, []byte("Cat"), 0600); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(b),
+		"begin 600 cat.txt\n#0V%T\n`\nend\n"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	data, h, err := uuencode.ReadFile(name)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(data), "Cat"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	want := uuencode.Header{Raw: "begin 600 cat.txt", Mode: 0600,
+		Name: "cat.txt"}
+	if diff := pretty.Compare(h, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	if _, _, err = uuencode.ReadFile(filepath.Join(dir, "none")); err == nil {
+		t.Error("Expecting error but got nil")
+	}
+}
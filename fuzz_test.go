@@ -0,0 +1,159 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+// tstFuzzSeeds are the seed inputs shared by the decoding fuzz targets.
+var tstFuzzSeeds = []string{
+	"",
+	"\n",
+	"begin 644 a.txt\n#86)C\n`\nend\n",
+	"begin 644 a.txt\r\n#86)C\r\n`\r\nend\r\n",
+	"junk\nbegin 644 a\n#86)C\n`\nend\ntail\nbegin 600 b\n!80``\n`\nend\n",
+	"begin 644 a\n#86)C\n",
+	"begin 644 a\n#86\n`\nend\n",
+	"begin 644 a\n%86)C\n`\nend\n",
+	"table\n `!\"#$%&'()*+,-./0123456789:;<=>?\n" +
+		"@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_\nbegin 644 a\n#86)C\n`\nend\n",
+	"begin 999 ../x\n \nend",
+	"begin",
+}
+
+// tstLongestLine returns the length of the longest line of b.
+func tstLongestLine(b []byte) int {
+	var max int
+	for _, l := range bytes.Split(b, []byte{'\n'}) {
+		if len(l) > max {
+			max = len(l)
+		}
+	}
+	return max
+}
+
+// checkTransformer checks every Transform call of t follows the
+// transform.Transformer contract.
+type checkTransformer struct {
+	t  transform.Transformer
+	tb testing.TB
+}
+
+func (c checkTransformer) Reset() { c.t.Reset() }
+
+func (c checkTransformer) Transform(dst, src []byte, atEOF bool) (int, int,
+	error) {
+	nDst, nSrc, err := c.t.Transform(dst, src, atEOF)
+	if nDst < 0 || nDst > len(dst) || nSrc < 0 || nSrc > len(src) {
+		c.tb.Fatalf("Transform(%d, %d, %v) = %d, %d out of range", len(dst),
+			len(src), atEOF, nDst, nSrc)
+	}
+	if err == nil && nSrc != len(src) {
+		c.tb.Fatalf("Transform(%d, %d, %v) = %d, %d with nil error",
+			len(dst), len(src), atEOF, nDst, nSrc)
+	}
+	if err == transform.ErrShortSrc && atEOF {
+		c.tb.Fatalf("Transform(%d, %d, true) = ErrShortSrc", len(dst),
+			len(src))
+	}
+	return nDst, nSrc, err
+}
+
+// checkDecodeErr fails if err is neither nil, a short buffer error nor
+// *DecodeError.
+func checkDecodeErr(tb testing.TB, err error) {
+	var derr *uuencode.DecodeError
+	if err != nil && !errors.As(err, &derr) {
+		tb.Fatalf("unexpected error %T: %v", err, err)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, s := range tstFuzzSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, in []byte) {
+		d := checkTransformer{t: uuencode.NewDecode(), tb: t}
+		want, _, err := transform.Bytes(d, in)
+		checkDecodeErr(t, err)
+		// Span must agree with Transform.
+		if n, _ := uuencode.NewDecode().Span(in, true); n > len(want) ||
+			!bytes.Equal(want[:n], in[:n]) {
+			t.Fatalf("span %d of %q does not match output %q", n, in, want)
+		}
+		// the output must not depend on how the input is split. Lines longer
+		// than an uuencoded line are only rejected when split.
+		if tstLongestLine(in) > 64 {
+			return
+		}
+		d.Reset()
+		r := transform.NewReader(iotest.OneByteReader(bytes.NewReader(in)), d)
+		got, rerr := ioutil.ReadAll(r)
+		if (err == nil) != (rerr == nil) {
+			t.Fatalf("error differs, whole input: %v, byte by byte: %v", err,
+				rerr)
+		}
+		if err == nil && !bytes.Equal(got, want) {
+			t.Fatalf("output differs, whole input: %q, byte by byte: %q",
+				want, got)
+		}
+	})
+}
+
+func FuzzMultiDecode(f *testing.F) {
+	for _, s := range tstFuzzSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, in []byte) {
+		d, _, ch := uuencode.NewMultiDecodeParts()
+		done := make(chan int)
+		go func() {
+			var n int
+			for p := range ch {
+				io.Copy(ioutil.Discard, p)
+				p.Close()
+				n++
+			}
+			done <- n
+		}()
+		_, _, err := transform.Bytes(checkTransformer{t: d, tb: t}, in)
+		d.Close()
+		n := <-done
+		checkDecodeErr(t, err)
+		if want := bytes.Count(in, []byte("begin")); n > want {
+			t.Fatalf("got %d contents from %d begin lines", n, want)
+		}
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte(""), true)
+	f.Add([]byte("I love you forever."), false)
+	f.Add(bytes.Repeat([]byte{0, 0xff, '\n'}, 100), true)
+	f.Fuzz(func(t *testing.T, data []byte, useGrave bool) {
+		e := checkTransformer{t: uuencode.NewEncode(useGrave, "\n"), tb: t}
+		enc, _, err := transform.Bytes(e, data)
+		if err != nil {
+			t.Fatal("encoding error:", err)
+		}
+		d := checkTransformer{t: uuencode.NewDecode(), tb: t}
+		got, _, err := transform.Bytes(d, enc)
+		if err != nil {
+			t.Fatalf("decoding %q error: %v", enc, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("round trip of %q gives %q", data, got)
+		}
+		got, err = uuencode.DecodeString(uuencode.EncodeToString(data))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("string round trip gives %q, %v", got, err)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package uuencode
+
+import (
+	"os"
+	"strings"
+)
+
+// Header holds the file information carried by the uuencode begin line, eg:
+// "begin 644 file.txt".
+type Header struct {
+	// Name is the file name. It is empty if the begin line has no file name.
+	Name string
+	// Mode is the file permission bits including os.ModeSetuid, os.ModeSetgid
+	// and os.ModeSticky. It is zero if the begin line has no valid permission
+	// bits.
+	Mode os.FileMode
+	// Raw is the begin line verbatim without the end of line characters and
+	// the leading byte order mark, for applications parsing the dialects that
+	// Name and Mode do not cover, eg: extra fields or odd spacing.
+	Raw string
+}
+
+// parseHeader parses the begin line without the end of line characters. The
+// file name is the rest of the line after the permission bits, it is unquoted
+// if it is quoted by quoteName.
+func parseHeader(line []byte) Header {
+	h := Header{Raw: string(line)}
+	as := strings.SplitN(h.Raw, " ", 3)
+	aslen := len(as)
+	if aslen > 2 {
+		h.Name = unquoteName(as[2])
+	}
+	if aslen > 1 {
+		h.Mode, _ = parseMode(as[1])
+	}
+	return h
+}
+
+// quoteName returns name quoted with double quotes if it has spaces or starts
+// with double quote, eg: "my file.txt", like GNU sharutils. Double quotes and
+// backslashes of the quoted name are escaped by backslash.
+func quoteName(name string) string {
+	if !strings.Contains(name, " ") && !strings.HasPrefix(name, `"`) {
+		return name
+	}
+	b := make([]byte, 0, len(name)+2)
+	b = append(b, '"')
+	for i := 0; i < len(name); i++ {
+		if name[i] == '"' || name[i] == '\\' {
+			b = append(b, '\\')
+		}
+		b = append(b, name[i])
+	}
+	return string(append(b, '"'))
+}
+
+// unquoteName returns s unquoted if it is quoted by quoteName, otherwise s as
+// is.
+func unquoteName(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	b := make([]byte, 0, len(s)-2)
+	for i := 1; i < len(s)-1; i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s)-1:
+			i++
+			b = append(b, s[i])
+		case c == '\\' || c == '"':
+			// dangling backslash or unescaped double quote.
+			return s
+		default:
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
+
+// Header returns the parsed begin line of the last encountered uuencoded
+// content. For multiple uuencoded contents decoding, it is updated when each
+// begin line is found, so the consumer goroutine may already see the begin line
+// of the next content; use PartHeader or NewMultiDecodeParts instead.
+func (d *Decode) Header() Header {
+	d.Lock()
+	defer d.Unlock()
+	return d.header
+}
+
+// OnHeader sets f to be called as soon as a begin line is parsed, before any of
+// its uuencoded body is decoded. For multiple uuencoded contents decoding, f is
+// called before the io.ReadCloser of the content is sent through the chan. f
+// runs in the goroutine calling Transform.
+func (d *Decode) OnHeader(f func(Header)) {
+	d.onHeader = f
+}
@@ -0,0 +1,215 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+var tstHeaderData = []struct {
+	in     string
+	header uuencode.Header
+}{
+	{
+		in: "begin 640 file.txt\n#0V%T\n`\nend\n",
+		header: uuencode.Header{Name: "file.txt", Mode: 0640,
+			Raw: "begin 640 file.txt"},
+	},
+	{
+		in: "begin 755 run.sh\r\n#0V%T\r\n`\r\nend\r\n",
+		header: uuencode.Header{Name: "run.sh", Mode: 0755,
+			Raw: "begin 755 run.sh"},
+	},
+	{
+		in:     "begin 999\n#0V%T\n`\nend\n",
+		header: uuencode.Header{Raw: "begin 999"},
+	},
+	{
+		in: "begin 644 \"my file.txt\"\n#0V%T\n`\nend\n",
+		header: uuencode.Header{Name: "my file.txt", Mode: 0644,
+			Raw: "begin 644 \"my file.txt\""},
+	},
+	{
+		in: "begin 644 my file.txt\n#0V%T\n`\nend\n",
+		header: uuencode.Header{Name: "my file.txt", Mode: 0644,
+			Raw: "begin 644 my file.txt"},
+	},
+	{
+		in: "begin  0644\tx.txt  size=3\r\n#0V%T\r\n`\r\nend\r\n",
+		// Name is wrong for the double spaces, Raw is for such dialects.
+		header: uuencode.Header{Name: "0644\tx.txt  size=3",
+			Raw: "begin  0644\tx.txt  size=3"},
+	},
+	{
+		in: "begin 644 \"bad\"name\"\n#0V%T\n`\nend\n",
+		header: uuencode.Header{Name: "\"bad\"name\"", Mode: 0644,
+			Raw: "begin 644 \"bad\"name\""},
+	},
+}
+
+func TestQuotedName(t *testing.T) {
+	tsts := []struct {
+		name, begin string
+	}{
+		{"plain.txt", "begin 644 plain.txt"},
+		{"my file.txt", `begin 644 "my file.txt"`},
+		{`a "b" \c`, `begin 644 "a \"b\" \\c"`},
+		{`"quoted"`, `begin 644 "\"quoted\""`},
+		{`back\slash`, `begin 644 back\slash`},
+	}
+	for _, tst := range tsts {
+		e := uuencode.NewEncodeWith(uuencode.WithFilename(tst.name))
+		out, _, err := transform.String(e, "Cat")
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		if begin := strings.SplitN(out, "\n", 2)[0]; begin != tst.begin {
+			t.Errorf("Got: %s Expecting: %s", begin, tst.begin)
+		}
+		if l := e.EncodedLen(3); l != len(out) {
+			t.Errorf("Got length: %d Expecting: %d", l, len(out))
+		}
+		d := uuencode.NewDecode()
+		if _, _, err = transform.String(d, out); err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		if name := d.Header().Name; name != tst.name {
+			t.Errorf("Got: %s Expecting: %s", name, tst.name)
+		}
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	for _, d := range tstHeaderData {
+		dec := uuencode.NewDecode()
+		_, err := ioutil.ReadAll(transform.NewReader(
+			bytes.NewBufferString(d.in), dec))
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		if diff := pretty.Compare(dec.Header(), d.header); diff != "" {
+			t.Errorf("Diff: %s", diff)
+		}
+		dec.Reset()
+		if diff := pretty.Compare(dec.Header(), uuencode.Header{}); diff != "" {
+			t.Errorf("Diff after reset: %s", diff)
+		}
+	}
+}
+
+func TestMultiDecodeHeader(t *testing.T) {
+	in := "begin 600 one.txt\n#0V%T\n`\nend\nbegin 700 two.txt\n#0V%T\n`\nend\n"
+	want := []string{"one.txt", "two.txt"}
+	d, _, ch := uuencode.NewMultiDecode()
+	got := make(chan []string)
+	go func() {
+		var names []string
+		for r := range ch {
+			// the begin line is parsed before the reader is sent.
+			names = append(names, d.Header().Name)
+			io.Copy(ioutil.Discard, r)
+		}
+		got <- names
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in), d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d.Close()
+	if diff := pretty.Compare(<-got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestMultiDecodePartHeader(t *testing.T) {
+	in := "begin 600 one.txt\n#0V%T\n`\nend\nbegin 700 two.txt\n#0V%T\n`\nend\n"
+	want := []uuencode.Header{
+		{Name: "one.txt", Mode: 0600, Raw: "begin 600 one.txt"},
+		{Name: "two.txt", Mode: 0700, Raw: "begin 700 two.txt"},
+	}
+	// the buffered pipe lets Transform parse the next begin line before the
+	// consumer reads the current content.
+	d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithPipeBuffer(64))
+	got := make(chan []uuencode.Header)
+	go func() {
+		var rs []io.ReadCloser
+		for r := range ch {
+			rs = append(rs, r)
+			d.Header() // safe to call but may be the next begin line.
+		}
+		var hs []uuencode.Header
+		for _, r := range rs {
+			h, ok := uuencode.PartHeader(r)
+			if !ok {
+				t.Error("Expecting header of", r)
+			}
+			hs = append(hs, h)
+			io.Copy(ioutil.Discard, r)
+		}
+		got <- hs
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in), d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d.Close()
+	if diff := pretty.Compare(<-got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	if _, ok := uuencode.PartHeader(bytes.NewBufferString(in)); ok {
+		t.Error("Expecting no header of non-decoded reader")
+	}
+}
+
+func TestDecodeOnHeader(t *testing.T) {
+	var got []uuencode.Header
+	dec := uuencode.NewDecode()
+	dec.OnHeader(func(h uuencode.Header) {
+		got = append(got, h)
+	})
+	in := "text\nbegin 640 file.txt\n#0V%T\n`\nend\n"
+	// dst can not hold any decoded byte, so the header must come first.
+	dst := make([]byte, len("text\n"))
+	nDst, _, err := dec.Transform(dst, []byte(in), true)
+	if err != transform.ErrShortDst {
+		t.Error("Got: ", err, " Expecting: ", transform.ErrShortDst)
+	}
+	if nDst != len(dst) {
+		t.Errorf("Want: %d\n Got: %d", len(dst), nDst)
+	}
+	want := []uuencode.Header{{Name: "file.txt", Mode: 0640,
+		Raw: "begin 640 file.txt"}}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestMultiDecodeOnHeader(t *testing.T) {
+	in := "begin 600 one.txt\n#0V%T\n`\nend\nbegin 700 two.txt\n#0V%T\n`\nend\n"
+	want := []string{"one.txt", "600", "two.txt", "700"}
+	var got []string
+	d, _, ch := uuencode.NewMultiDecode()
+	d.OnHeader(func(h uuencode.Header) {
+		got = append(got, h.Name, fmt.Sprintf("%o", h.Mode))
+	})
+	go func() {
+		for r := range ch {
+			io.Copy(ioutil.Discard, r)
+		}
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in), d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d.Close()
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
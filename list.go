@@ -0,0 +1,54 @@
+package uuencode
+
+import "io"
+
+// ListEntry is one uuencoded content found by List.
+type ListEntry struct {
+	// Header is the parsed begin line.
+	Header Header
+	// Offset is the byte offset of the begin line.
+	Offset int64
+	// Lines is the number of body lines, without the grave and end marker
+	// lines.
+	Lines int
+	// Size is the length in bytes of the decoded content given by the length
+	// characters of the body lines.
+	Size int64
+}
+
+// List calls f with ListEntry of every uuencoded content in r in input order,
+// without decoding the body lines, eg: for indexing the attachments of huge
+// mail archives. Only the length characters of body lines are read, so the
+// body lines are not validated. A content is a plausible begin line (see
+// IndexUuencode) through the next end marker line like FindUuencode, content
+// without end marker line is not reported. The error of f stops the listing
+// and is returned.
+func List(r io.Reader, f func(ListEntry) error) error {
+	sc := newLineScanner(r)
+	var cur *ListEntry
+	for {
+		line, off, err := sc.next()
+		switch {
+		case line == nil:
+			// too long line can not be part of uuencoded content.
+			cur = nil
+		case plausibleBegin(line):
+			cur = &ListEntry{Header: parseHeader(line), Offset: off}
+		case cur == nil:
+		case string(line) == uuEndMarker:
+			if ferr := f(*cur); ferr != nil {
+				return ferr
+			}
+			cur = nil
+		case len(line) > 0 && line[0] != uuPadding &&
+			uuDecTable[line[0]] != uuInvalid:
+			cur.Lines++
+			cur.Size += int64(uuDecTable[line[0]])
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
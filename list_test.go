@@ -0,0 +1,46 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+)
+
+func TestList(t *testing.T) {
+	big := uuencode.EncodeToString(make([]byte, 100))
+	in := "text\n" + big + "junk\nbegin 600 b.txt\r\n#0V%T\r\n`\r\nend\r\n" +
+		"begin 644 c.txt\n#0V%T\n"
+	var got []uuencode.ListEntry
+	err := uuencode.List(bytes.NewBufferString(in),
+		func(e uuencode.ListEntry) error {
+			got = append(got, e)
+			return nil
+		})
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	want := []uuencode.ListEntry{
+		{Header: uuencode.Header{Name: "filename", Mode: 0644,
+			Raw: "begin 644 filename"}, Offset: 5, Lines: 3, Size: 100},
+		{Header: uuencode.Header{Name: "b.txt", Mode: 0600,
+			Raw: "begin 600 b.txt"}, Offset: int64(5 + len(big) + 5),
+			Lines: 1, Size: 3},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// the error of f stops the listing.
+	errStop := errors.New("stop")
+	var n int
+	err = uuencode.List(bytes.NewBufferString(in),
+		func(uuencode.ListEntry) error {
+			n++
+			return errStop
+		})
+	if err != errStop || n != 1 {
+		t.Error("Got: ", err, n, " Expecting: ", errStop)
+	}
+}
@@ -1,6 +1,9 @@
 package uuencode
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
 var tstMiniConvertData = []struct {
 	in, out string
@@ -19,7 +22,9 @@ func Test_miniConvert(t *testing.T) {
 	for _, d := range tstMiniConvertData {
 		outlen := len(d.out)
 		out := make([]byte, outlen+2)
-		miniConvert(out, []byte(d.in))
+		if _, ok := miniConvert(out, []byte(d.in)); !ok {
+			t.Errorf("Want: %s\n Got invalid character", d.out)
+		}
 		out = out[:outlen]
 		if string(out) != d.out {
 			t.Errorf("Want: %s\n Got: %s", d.out, string(out))
@@ -27,6 +32,15 @@ func Test_miniConvert(t *testing.T) {
 	}
 }
 
+func Test_miniConvertInvalid(t *testing.T) {
+	for _, in := range []string{"0V%\x7f", "\t0V%", "0Va%", "0V%T0V%~"} {
+		var out [6]byte
+		if _, ok := miniConvert(out[:], []byte(in)); ok {
+			t.Errorf("Want invalid character for %q", in)
+		}
+	}
+}
+
 var tstMiniEncodeData = []struct {
 	n       int
 	grave   bool
@@ -141,3 +155,68 @@ func TestLineEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestBodyDecNoAlloc(t *testing.T) {
+	var src []byte
+	for i := 0; i < 100; i++ {
+		src = append(src, "M86)C9&5F9VAI:FML;6YO<'%R<W1U=G=X>7IA8F-D969G:&EJ:VQM;F]P<7)S\n"...)
+	}
+	src = append(src, "`\nend\n"...)
+	dst := make([]byte, len(src))
+	var u uuBodyDec
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, _, err := u.Transform(dst, src, true); err != errFoundEOF {
+			t.Fatal("Got: ", err, " Expecting: ", errFoundEOF)
+		}
+	})
+	if allocs != 0 {
+		t.Error("Got allocs: ", allocs, " Expecting: 0")
+	}
+}
+
+func TestFullLineEncode(t *testing.T) {
+	src := make([]byte, maxSingleLine)
+	for n := 0; n < 64; n++ {
+		for i := range src {
+			src[i] = byte(i*n*7 + n)
+		}
+		for _, grave := range []bool{true, false} {
+			want := make([]byte, maxEncLine-1)
+			got := make([]byte, maxEncLine-1)
+			lineEncode(want, src, maxSingleLine, grave)
+			fullLineEncode(got, src, grave)
+			if string(got) != string(want) {
+				t.Errorf("Want: %s\n Got: %s", want, got)
+			}
+		}
+	}
+}
+
+func TestTransformNoAlloc(t *testing.T) {
+	src := make([]byte, 1000)
+	dst := make([]byte, 4096)
+	e := NewEncode(true, "\r\n", "pp.txt", "600")
+	allocs := testing.AllocsPerRun(10, func() {
+		e.Reset()
+		if _, _, err := e.Transform(dst, src, true); err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+	})
+	if allocs != 0 {
+		t.Error("encode got allocs: ", allocs, " Expecting: 0")
+	}
+	n, _, _ := e.Transform(dst, src, true)
+	body := dst[bytes.IndexByte(dst, '\n')+1 : n]
+	d := NewDecode()
+	d.state = uuBody
+	out := make([]byte, len(src))
+	allocs = testing.AllocsPerRun(10, func() {
+		d.state = uuBody
+		if _, _, err := d.Transform(out, body, true); err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+	})
+	if allocs != 0 {
+		t.Error("decode got allocs: ", allocs, " Expecting: 0")
+	}
+}
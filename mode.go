@@ -0,0 +1,72 @@
+package uuencode
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// ErrBadMode indicates the begin line file permission is not a valid octal
+// permission bits.
+var ErrBadMode = errors.New("uuencode: bad file permission mode")
+
+// formatMode returns the begin line form of mode which is the permission bits
+// in base-8.
+func formatMode(mode os.FileMode) string {
+	return strconv.FormatUint(uint64(mode.Perm()), 8)
+}
+
+// parseMode parses the begin line file permission s which must be octal
+// permission bits, eg: "644" or "0755". The fourth digit of setuid, setgid and
+// sticky bits, eg: "4755", is normalized into os.ModeSetuid, os.ModeSetgid and
+// os.ModeSticky.
+func parseMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, ErrBadMode
+	}
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || m&^07777 != 0 {
+		return 0, ErrBadMode
+	}
+	mode := os.FileMode(m) & os.ModePerm
+	if m&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if m&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if m&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
+
+// SetMode sets the file permission bits outputted at the begin line. Only the
+// permission bits of mode are used, so os.FileInfo Mode can be used directly.
+func (e *Encode) SetMode(mode os.FileMode) {
+	e.permit = formatMode(mode)
+}
+
+// Mode returns the file permission bits outputted at the begin line.
+func (e *Encode) Mode() os.FileMode {
+	m, _ := parseMode(e.permit)
+	return m
+}
+
+// ResetFile is like ResetAll but the permission bits is provided as
+// os.FileMode.
+func (e *Encode) ResetFile(mode os.FileMode, name string) {
+	e.ResetAll(formatMode(mode), name)
+}
+
+// Mode returns the file permission bits of the last decoded begin line.
+// *HeaderError wrapping ErrBadMode is returned if the begin line has no valid
+// permission bits.
+func (d *Decode) Mode() (os.FileMode, error) {
+	h := d.Header()
+	m, err := parseMode(modeField(h.Raw))
+	if err != nil {
+		return 0, &HeaderError{Raw: h.Raw, Err: err}
+	}
+	return m, nil
+}
@@ -0,0 +1,79 @@
+package uuencode
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+var tstParseModeData = []struct {
+	in   string
+	mode os.FileMode
+	err  error
+}{
+	{in: "644", mode: 0644},
+	{in: "0755", mode: 0755},
+	{in: "7", mode: 07},
+	{in: "777", mode: 0777},
+	{in: "", err: ErrBadMode},
+	{in: "999", err: ErrBadMode},
+	{in: "1777", mode: os.ModeSticky | 0777},
+	{in: "4755", mode: os.ModeSetuid | 0755},
+	{in: "02750", mode: os.ModeSetgid | 0750},
+	{in: "10644", err: ErrBadMode},
+	{in: "-644", err: ErrBadMode},
+	{in: "rw-", err: ErrBadMode},
+}
+
+func Test_parseMode(t *testing.T) {
+	for _, d := range tstParseModeData {
+		mode, err := parseMode(d.in)
+		if err != d.err || mode != d.mode {
+			t.Errorf("in=%q Want: %v %v\n Got: %v %v", d.in, d.mode, d.err,
+				mode, err)
+		}
+	}
+}
+
+func TestEncodeSetMode(t *testing.T) {
+	e := NewEncode(true, "\n")
+	e.SetMode(os.ModeDir | 0750)
+	if e.permit != "750" {
+		t.Errorf("Want: 750\n Got: %s", e.permit)
+	}
+	if e.Mode() != 0750 {
+		t.Errorf("Want: %v\n Got: %v", os.FileMode(0750), e.Mode())
+	}
+	e.ResetFile(0600, "pp.txt")
+	if e.permit != "600" || e.name != "pp.txt" {
+		t.Errorf("Want: 600 pp.txt\n Got: %s %s", e.permit, e.name)
+	}
+}
+
+func TestDecodeMode(t *testing.T) {
+	d := NewDecode()
+	dst := make([]byte, 64)
+	_, _, err := d.Transform(dst, []byte("begin 640 file.txt\n#0V%T\n`\nend\n"),
+		true)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if mode, err := d.Mode(); err != nil || mode != 0640 {
+		t.Errorf("Want: %v\n Got: %v %v", os.FileMode(0640), mode, err)
+	}
+	d.Reset()
+	if _, err := d.Mode(); !errors.Is(err, ErrBadMode) {
+		t.Error("Got: ", err, " Expecting: ", ErrBadMode)
+	}
+	d.Reset()
+	_, _, err = d.Transform(dst, []byte("begin 999 file.txt\n#0V%T\n`\nend\n"),
+		true)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	var herr *HeaderError
+	if _, err := d.Mode(); !errors.As(err, &herr) ||
+		herr.Raw != "begin 999 file.txt" || !errors.Is(err, ErrBadMode) {
+		t.Error("Got: ", err, " Expecting: ", ErrBadMode)
+	}
+}
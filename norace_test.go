@@ -0,0 +1,8 @@
+//go:build !race
+// +build !race
+
+package uuencode_test
+
+// raceEnabled reports the race detector is on, which makes allocation counts
+// unreliable.
+const raceEnabled = false
@@ -0,0 +1,256 @@
+package uuencode
+
+import (
+	"hash"
+	"io"
+	"os"
+)
+
+// EncodeOption configures Encode created by NewEncodeWith.
+type EncodeOption func(*Encode)
+
+// WithFilename sets the file name outputted at the begin line. The name with
+// spaces is quoted, eg: "my file.txt".
+func WithFilename(name string) EncodeOption {
+	return func(e *Encode) {
+		e.name = name
+	}
+}
+
+// WithMode sets the file permission bits outputted at the begin line. Only the
+// permission bits of mode are used.
+func WithMode(mode os.FileMode) EncodeOption {
+	return func(e *Encode) {
+		e.SetMode(mode)
+	}
+}
+
+// WithEOL sets the end of line pattern, eg: \r\n or \n.
+func WithEOL(eol string) EncodeOption {
+	return func(e *Encode) {
+		e.eol = eol
+	}
+}
+
+// WithGravePadding sets whether grave is used as padding and zero character
+// instead of space.
+func WithGravePadding(useGrave bool) EncodeOption {
+	return func(e *Encode) {
+		e.useGrave = useGrave
+	}
+}
+
+// WithTable sets the custom 64 characters set that replaces the standard
+// uuencode characters. The historical table section defining it is outputted
+// before the begin line, so the decoder can use it. Transform fails with
+// ErrBadTable if table is not 64 distinct printable ASCII characters.
+func WithTable(table string) EncodeOption {
+	return func(e *Encode) {
+		e.alpha, e.tableErr = NewAlphabet(table)
+		e.emitTable = true
+	}
+}
+
+// WithAlphabet sets the custom characters set that replaces the standard
+// uuencode characters, eg: XXAlphabet. Unlike WithTable, the table section is
+// not outputted, so the decoder must be given the same Alphabet.
+func WithAlphabet(a *Alphabet) EncodeOption {
+	return func(e *Encode) {
+		e.alpha, e.tableErr, e.emitTable = a, nil, false
+	}
+}
+
+// WithCRC32Trailer sets whether the trailer line of the CRC-32 (IEEE) of the
+// source bytes, eg: "crc32 1a2b3c4d", is outputted after the end marker line
+// like some UUDeview lineage encoders. Decode verifies the trailer line when
+// it is present. Decoders that do not know it see a text line after the
+// uuencoded content.
+func WithCRC32Trailer(emit bool) EncodeOption {
+	return func(e *Encode) {
+		e.crcTrailer = emit
+	}
+}
+
+// WithSizeTrailer sets whether the trailer line of the number of source bytes,
+// eg: "size 12345", is outputted after the end marker line like some
+// historical encoders. It is outputted before the CRC-32 trailer line. Decode
+// consumes the trailer line when it matches, otherwise it is kept as text.
+func WithSizeTrailer(emit bool) EncodeOption {
+	return func(e *Encode) {
+		e.sizeTrailer = emit
+	}
+}
+
+// NewBodyEncode is like NewEncodeWith but only outputs the uuencoded data lines,
+// without the begin line, grave line and end marker line. It is for embedding
+// uuencoded payloads in other container format. The begin line options are
+// ignored.
+func NewBodyEncode(opts ...EncodeOption) *Encode {
+	e := NewEncodeWith(opts...)
+	e.bodyOnly = true
+	return e
+}
+
+// NewEncodeWith return *Encode configured by opts. Without any option, it has
+// the same setting as Uue.NewEncoder: grave padding, \n end of line and begin
+// line of "begin 644 filename".
+func NewEncodeWith(opts ...EncodeOption) *Encode {
+	e := NewEncode(true, "\n")
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// DecodeOption configures Decode created by NewDecodeWith or
+// NewMultiDecodeWith.
+type DecodeOption func(*Decode)
+
+// WithLenient sets whether invalid uuencoded body lines, eg: blank lines or
+// banners inserted by mail gateways, are skipped instead of failing the
+// decoding. Every skipped line is reported as Warning.
+func WithLenient(lenient bool) DecodeOption {
+	return func(d *Decode) {
+		d.lenient = lenient
+	}
+}
+
+// WithRepad sets whether uuencoded body lines that trailing padding characters
+// were stripped are re-padded base on the length character, and trailing white
+// spaces added after the padding are trimmed. Every repaired line is reported
+// as Warning.
+func WithRepad(repad bool) DecodeOption {
+	return func(d *Decode) {
+		d.repad = repad
+	}
+}
+
+// WithRejoin sets whether uuencoded body lines that were wrapped by mail
+// gateways, eg: at 72 or 76 characters, are joined with their continuation
+// lines before decoding. A line is wrapped when its length character expects
+// more characters than the line has, and the next line has exactly the missing
+// characters but no matching length character of its own. Every joined line is
+// reported as Warning.
+func WithRejoin(rejoin bool) DecodeOption {
+	return func(d *Decode) {
+		d.rejoin = rejoin
+	}
+}
+
+// WithConcat sets whether single decoding decodes every uuencoded content it
+// encounters instead of only the first one. Decoded bytes of all contents are
+// concatenated into the output and bytes outside uuencoded contents are passed
+// through as is. After the end marker line, decoding goes back to looking for
+// the next begin line instead of copying the rest of input. It has no effect on
+// multiple uuencoded contents decoding.
+func WithConcat(concat bool) DecodeOption {
+	return func(d *Decode) {
+		d.concat = concat
+	}
+}
+
+// WithStripBOM sets whether the UTF-8 byte order mark at the start of the
+// input, eg: files saved by Windows editors, is dropped instead of passed
+// through. The begin line right after the byte order mark is recognized
+// either way.
+func WithStripBOM(strip bool) DecodeOption {
+	return func(d *Decode) {
+		d.stripBOM = strip
+	}
+}
+
+// WithMaxPartSize limits the decoded bytes of each uuencoded content to n.
+// Decoding fails with ErrTooLarge once the limit is exceeded. Zero means no
+// limit.
+func WithMaxPartSize(n int64) DecodeOption {
+	return func(d *Decode) {
+		d.maxPart = n
+	}
+}
+
+// WithMaxTotalSize limits the decoded bytes of all uuencoded contents to n.
+// Decoding fails with ErrTooLarge once the limit is exceeded. Zero means no
+// limit.
+func WithMaxTotalSize(n int64) DecodeOption {
+	return func(d *Decode) {
+		d.maxTotal = n
+	}
+}
+
+// WithMaxParts limits the number of uuencoded contents decoded to n. Decoding
+// fails with ErrTooManyParts when the begin line of one more content is found.
+// Zero means no limit.
+func WithMaxParts(n int) DecodeOption {
+	return func(d *Decode) {
+		d.maxParts = n
+	}
+}
+
+// WithPipeBuffer buffers up to n decoded bytes between decoder and consumer of
+// multiple uuencoded contents decoding, so Transform does not block on every
+// write when consumer is slightly slower. Zero means no buffering. It has no
+// effect on single decoding.
+func WithPipeBuffer(n int) DecodeOption {
+	return func(d *Decode) {
+		d.pipeBuf = n
+	}
+}
+
+// WithTextEOL decodes in text mode that CRLF, CR and LF of the decoded
+// contents are converted to eol, eg: "\n" or "\r\n", for text files authored
+// on other systems. Empty eol means no conversion which is the default.
+func WithTextEOL(eol string) DecodeOption {
+	return func(d *Decode) {
+		d.textEOL = eol
+	}
+}
+
+// WithDigest computes the digest of the decoded bytes of every uuencoded
+// content with the hash created by newHash, eg: sha256.New, while it is
+// decoded, so no second pass over the decoded bytes is needed. The digest is
+// provided by Decode.Digest, MultiPart.Digest and PartDigest. Nil newHash
+// computes no digest which is the default.
+func WithDigest(newHash func() hash.Hash) DecodeOption {
+	return func(d *Decode) {
+		d.newHash = newHash
+	}
+}
+
+// WithDecodeAlphabet sets the custom characters set of the uuencoded contents,
+// eg: XXAlphabet. The table section before the begin line still overrides it
+// for that content.
+func WithDecodeAlphabet(a *Alphabet) DecodeOption {
+	return func(d *Decode) {
+		d.baseAlpha = a
+		d.alpha = a
+	}
+}
+
+// NewDecodeWith is like NewDecode but configured by opts.
+func NewDecodeWith(opts ...DecodeOption) *Decode {
+	d := NewDecode()
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// NewBodyDecode is like NewDecodeWith but decodes bare uuencoded body lines
+// without the begin line. Decoding stops at blank line, grave line or EOF, any
+// bytes after the stopping line are passed through as is. It is for callers
+// that strip the framing themselves, eg: MIME x-uuencode bodies.
+func NewBodyDecode(opts ...DecodeOption) *Decode {
+	d := NewDecodeWith(opts...)
+	d.bodyOnly = true
+	return d
+}
+
+// NewMultiDecodeWith is like NewMultiDecode but configured by opts.
+func NewMultiDecodeWith(opts ...DecodeOption) (*Decode, func(),
+	<-chan io.ReadCloser) {
+	d, cancel, ch := NewMultiDecode()
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d, cancel, ch
+}
@@ -0,0 +1,537 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+var tstEncodeWithData = []struct {
+	opts []uuencode.EncodeOption
+	out  string
+}{
+	{
+		out: "begin 644 filename\n322!L;W9E('EO=2!F;W)E=F5R+@``\n`\nend\n",
+	},
+	{
+		opts: []uuencode.EncodeOption{
+			uuencode.WithFilename("pp.txt"),
+			uuencode.WithMode(0777),
+		},
+		out: "begin 777 pp.txt\n322!L;W9E('EO=2!F;W)E=F5R+@``\n`\nend\n",
+	},
+	{
+		opts: []uuencode.EncodeOption{
+			uuencode.WithMode(0600),
+			uuencode.WithEOL("\r\n"),
+			uuencode.WithGravePadding(false),
+		},
+		out: "begin 600 filename\r\n322!L;W9E('EO=2!F;W)E=F5R+@  \r\n`\r\nend\r\n",
+	},
+}
+
+func TestNewEncodeWith(t *testing.T) {
+	for _, d := range tstEncodeWithData {
+		br := bytes.NewBufferString("I love you forever.")
+		r := transform.NewReader(br, uuencode.NewEncodeWith(d.opts...))
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal("err:", err)
+		}
+		if diff := pretty.Compare(string(got), d.out); diff != "" {
+			t.Errorf("Diff: %s", diff)
+		}
+	}
+}
+
+func TestNewBodyEncode(t *testing.T) {
+	tsts := []struct {
+		in, out string
+		opts    []uuencode.EncodeOption
+	}{
+		{"I love you forever.", "322!L;W9E('EO=2!F;W)E=F5R+@``\n", nil},
+		{"I love you forever.", "322!L;W9E('EO=2!F;W)E=F5R+@  \r\n",
+			[]uuencode.EncodeOption{uuencode.WithEOL("\r\n"),
+				uuencode.WithGravePadding(false)}},
+		{"", "", nil},
+		{strings.Repeat("a", 45), "M" + strings.Repeat("86%A", 15) + "\n", nil},
+	}
+	for _, d := range tsts {
+		got, _, err := transform.String(uuencode.NewBodyEncode(d.opts...), d.in)
+		if err != nil {
+			t.Fatal("err:", err)
+		}
+		if diff := pretty.Compare(got, d.out); diff != "" {
+			t.Errorf("Diff: %s", diff)
+		}
+	}
+}
+
+func TestNewBodyDecode(t *testing.T) {
+	tsts := []struct {
+		in, out string
+		err     error
+	}{
+		{"322!L;W9E('EO=2!F;W)E=F5R+@``\n", "I love you forever.", nil},
+		{"322!L;W9E('EO=2!F;W)E=F5R+@``", "I love you forever.", nil},
+		{"322!L;W9E('EO=2!F;W)E=F5R+@``\r\n`\r\nend\r\n",
+			"I love you forever.end\r\n", nil},
+		{"#86)C\n\nrest\n", "abcrest\n", nil},
+		{"#86)C\r\n\r\n", "abc", nil},
+		{"", "", nil},
+		{"#86)C\nnot uuencoded\n", "abc", uuencode.ErrBadUUDec},
+	}
+	for i, d := range tsts {
+		got, _, err := transform.String(uuencode.NewBodyDecode(), d.in)
+		if !errors.Is(err, d.err) || d.err == nil && err != nil {
+			t.Error(i, "Got: ", err, " Expecting: ", d.err)
+			continue
+		}
+		if d.err != nil {
+			continue
+		}
+		if diff := pretty.Compare(got, d.out); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+	}
+	// round trip with NewBodyEncode.
+	src := strings.Repeat("I love you forever.", 100)
+	enc, _, err := transform.String(uuencode.NewBodyEncode(), src)
+	if err != nil {
+		t.Fatal("err:", err)
+	}
+	got, _, err := transform.String(uuencode.NewBodyDecode(), enc)
+	if err != nil || got != src {
+		t.Error("round trip got: ", err)
+	}
+}
+
+func TestDecodeTextEOL(t *testing.T) {
+	text := strings.Repeat("dos line\r\nmac line\runix line\n", 200)
+	tsts := []struct {
+		eol, want string
+	}{
+		{"\n", strings.Repeat("dos line\nmac line\nunix line\n", 200)},
+		{"\r\n",
+			strings.Repeat("dos line\r\nmac line\r\nunix line\r\n", 200)},
+		{"", text},
+	}
+	encoded := uuencode.EncodeToString([]byte(text))
+	for _, d := range tsts {
+		// CRLF is split across lines and Transform calls.
+		for _, r := range []io.Reader{strings.NewReader(encoded),
+			iotest.OneByteReader(strings.NewReader(encoded))} {
+			got, err := ioutil.ReadAll(transform.NewReader(
+				io.MultiReader(strings.NewReader("junk\r\n"), r),
+				uuencode.NewDecodeWith(uuencode.WithTextEOL(d.eol))))
+			if err != nil {
+				t.Fatal("err:", err)
+			}
+			want := "junk\r\n" + d.want
+			if diff := pretty.Compare(string(got), want); diff != "" {
+				t.Errorf("%q Diff: %s", d.eol, diff)
+			}
+		}
+	}
+	d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithTextEOL("\n"))
+	done := make(chan string)
+	go func() {
+		for r := range ch {
+			b, _ := ioutil.ReadAll(r)
+			done <- string(b)
+		}
+	}()
+	if _, err := ioutil.ReadAll(transform.NewReader(
+		strings.NewReader(encoded), d)); err != nil {
+		t.Fatal("err:", err)
+	}
+	got := <-done
+	d.Close()
+	if diff := pretty.Compare(got, tsts[0].want); diff != "" {
+		t.Errorf("multi Diff: %s", diff)
+	}
+}
+
+func TestDecodeLenient(t *testing.T) {
+	in := "begin 644 file.txt\n#0V%T\n\n-- cut here --\r\n#0V%T\n`\nend\n"
+	var got []uuencode.Warning
+	d := uuencode.NewDecodeWith(uuencode.WithLenient(true))
+	d.OnWarning(func(w uuencode.Warning) {
+		got = append(got, w)
+	})
+	out, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in),
+		d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(out), "CatCat"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	want := []uuencode.Warning{
+		{Line: 3, Offset: 25, Reason: "empty line"},
+		{Line: 4, Offset: 26, Reason: "length is not multiple of 4",
+			Text: "-- cut here --"},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// without lenient mode the same input fails.
+	_, err = ioutil.ReadAll(uuencode.NewReader(bytes.NewBufferString(in)))
+	if err == nil {
+		t.Error("Expecting error but nil error")
+	}
+}
+
+func TestMultiDecodeLenient(t *testing.T) {
+	in := "begin 644 one.txt\n#0V%T\n\n`\nend\n" +
+		"begin 644 two.txt\n~~~~~\n#0V%T\n`\nend\n"
+	d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithLenient(true))
+	var parts []int
+	d.OnWarning(func(w uuencode.Warning) {
+		parts = append(parts, w.Part)
+	})
+	got := make(chan []string)
+	go func() {
+		var s []string
+		for r := range ch {
+			b, _ := ioutil.ReadAll(r)
+			s = append(s, string(b))
+		}
+		got <- s
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in), d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d.Close()
+	if diff := pretty.Compare(<-got, []string{"Cat", "Cat"}); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	if diff := pretty.Compare(parts, []int{0, 1}); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestDecodeRepad(t *testing.T) {
+	// "I love you forever." with trailing grave stripped from the first line
+	// and trailing spaces added to the second line.
+	in := "begin 644 file.txt\n322!L;W9E('EO=2!F;W)E=F5R+@\n#0V%T  \n`\nend\n"
+	var got []string
+	d := uuencode.NewDecodeWith(uuencode.WithRepad(true))
+	d.OnWarning(func(w uuencode.Warning) {
+		got = append(got, w.Reason)
+	})
+	out, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in),
+		d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(out), "I love you forever.Cat"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	want := []string{"re-padded line", "trimmed trailing white spaces"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	_, err = ioutil.ReadAll(uuencode.NewReader(bytes.NewBufferString(in)))
+	if err == nil {
+		t.Error("Expecting error but nil error")
+	}
+}
+
+func TestDecodeRejoin(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog. Cat")
+	enc := uuencode.EncodeToString(data)
+	lines := strings.Split(enc, "\n")
+	// wrap the first body line at 40 characters.
+	lines[1] = lines[1][:40] + "\r\n" + lines[1][40:]
+	in := strings.Join(lines, "\n")
+	var got []uuencode.Warning
+	d := uuencode.NewDecodeWith(uuencode.WithRejoin(true))
+	d.OnWarning(func(w uuencode.Warning) {
+		got = append(got, w)
+	})
+	out, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in),
+		d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(out), string(data)); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	want := []uuencode.Warning{{Line: 2, Offset: int64(len(lines[0]) + 1),
+		Reason: "rejoined wrapped line", Text: lines[1][:40]}}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// every byte at a time gives the same result.
+	d.Reset()
+	out, err = ioutil.ReadAll(transform.NewReader(iotest.OneByteReader(
+		bytes.NewBufferString(in)), d))
+	if err != nil || string(out) != string(data) {
+		t.Error("Got: ", string(out), err)
+	}
+	_, err = ioutil.ReadAll(uuencode.NewReader(bytes.NewBufferString(in)))
+	if err == nil {
+		t.Error("Expecting error but nil error")
+	}
+	// a short line followed by a body line of its own is not joined.
+	in = "begin 644 a\n#0V%\n#0V%T\n`\nend\n"
+	_, err = ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in),
+		uuencode.NewDecodeWith(uuencode.WithRejoin(true))))
+	if !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
+	}
+}
+
+func TestDecodeConcat(t *testing.T) {
+	in := "text1\nbegin 644 one.txt\n#0V%T\n`\nend\ntext2\n" +
+		"begin 644 two.txt\n#1&]G\n`\nend\ntext3\n"
+	d := uuencode.NewDecodeWith(uuencode.WithConcat(true))
+	out, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in),
+		d))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	want := "text1\nCattext2\nDogtext3\n"
+	if diff := pretty.Compare(string(out), want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// without any uuencoded content it fails like the default single decoding.
+	d.Reset()
+	_, err = ioutil.ReadAll(transform.NewReader(bytes.NewBufferString("text"),
+		d))
+	if err == nil {
+		t.Error("Expecting error but nil error")
+	}
+}
+
+func TestDecodeStripBOM(t *testing.T) {
+	const bom = "\xef\xbb\xbf"
+	tsts := []struct {
+		in, out string
+		strip   bool
+	}{
+		{bom + "begin 644 a\n#86)C\n`\nend\n", "abc", false},
+		{bom + "begin 644 a\r\n#86)C\r\n`\r\nend\r\n", "abc", true},
+		{bom + "text\nbegin 644 a\n#86)C\n`\nend\n", bom + "text\nabc", false},
+		{bom + "text\nbegin 644 a\n#86)C\n`\nend\n", "text\nabc", true},
+		{"text\n" + bom + "begin 644 a\n#86)C\n`\nend\n" + bom,
+			"text\n" + bom + "begin 644 a\n#86)C\n`\nend\n" + bom, true},
+	}
+	for i, tst := range tsts {
+		d := uuencode.NewDecodeWith(uuencode.WithStripBOM(tst.strip))
+		// one byte at a time splits the byte order mark across calls.
+		r := transform.NewReader(iotest.OneByteReader(
+			bytes.NewBufferString(tst.in)), d)
+		out, err := ioutil.ReadAll(r)
+		if i == len(tsts)-1 {
+			// the byte order mark is only recognized at the start.
+			if err == nil {
+				t.Error(i, "Expecting error but nil error")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		if diff := pretty.Compare(string(out), tst.out); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+		if i == 0 && d.Header().Name != "a" {
+			t.Error("Got name:", d.Header().Name, "Expecting: a")
+		}
+	}
+}
+
+func TestDecodeMaxSize(t *testing.T) {
+	src := bytes.Repeat([]byte("0123456789"), 100)
+	one := uuencode.EncodeToString(src)
+	var tstData = []struct {
+		opts []uuencode.DecodeOption
+		in   string
+		err  error
+	}{
+		{
+			opts: []uuencode.DecodeOption{uuencode.WithMaxPartSize(1000)},
+			in:   one,
+		},
+		{
+			opts: []uuencode.DecodeOption{uuencode.WithMaxPartSize(999)},
+			in:   one,
+			err:  uuencode.ErrTooLarge,
+		},
+		{
+			opts: []uuencode.DecodeOption{uuencode.WithMaxPartSize(1000),
+				uuencode.WithConcat(true)},
+			in: one + one,
+		},
+		{
+			opts: []uuencode.DecodeOption{uuencode.WithMaxTotalSize(1500),
+				uuencode.WithConcat(true)},
+			in:  one + one,
+			err: uuencode.ErrTooLarge,
+		},
+	}
+	for i, d := range tstData {
+		dec := uuencode.NewDecodeWith(d.opts...)
+		out, err := ioutil.ReadAll(transform.NewReader(
+			bytes.NewBufferString(d.in), dec))
+		if d.err == nil {
+			if err != nil {
+				t.Errorf("%d: Expecting non-error but got err: %v", i, err)
+			} else if len(out)%len(src) != 0 {
+				t.Errorf("%d: unexpected decoded length %d", i, len(out))
+			}
+		} else if !errors.Is(err, d.err) {
+			t.Errorf("%d: Got: %v Expecting: %v", i, err, d.err)
+		}
+	}
+}
+
+func TestMultiDecodeMaxSize(t *testing.T) {
+	one := uuencode.EncodeToString(bytes.Repeat([]byte("0123456789"), 100))
+	d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithMaxTotalSize(1500))
+	rerr := make(chan error)
+	go func() {
+		var err error
+		for r := range ch {
+			_, err = io.Copy(ioutil.Discard, r)
+		}
+		rerr <- err
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(one+one), d))
+	d.Close()
+	if !errors.Is(err, uuencode.ErrTooLarge) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrTooLarge)
+	}
+	// reader of the failing content gets the same error.
+	if err = <-rerr; !errors.Is(err, uuencode.ErrTooLarge) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrTooLarge)
+	}
+}
+
+func TestMultiDecodeMaxParts(t *testing.T) {
+	one := uuencode.EncodeToString([]byte("I love you forever."))
+	for _, n := range []int{1, 2, 3} {
+		d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithMaxParts(2))
+		parts := make(chan int)
+		go func() {
+			var i int
+			for r := range ch {
+				io.Copy(ioutil.Discard, r)
+				i++
+			}
+			parts <- i
+		}()
+		_, err := ioutil.ReadAll(transform.NewReader(
+			bytes.NewBufferString(strings.Repeat(one, n)), d))
+		d.Close()
+		if n > 2 {
+			if !errors.Is(err, uuencode.ErrTooManyParts) {
+				t.Error("Got: ", err, " Expecting: ", uuencode.ErrTooManyParts)
+			}
+			var derr *uuencode.DecodeError
+			if !errors.As(err, &derr) || derr.Part != 2 {
+				t.Error("Expecting DecodeError of part 2 but got", err)
+			}
+		} else if err != nil {
+			t.Error("Expecting non-error but got err:", err)
+		}
+		if got, want := <-parts, n; n > 2 && got != 2 || n <= 2 && got != want {
+			t.Errorf("n=%d: unexpected parts %d", n, got)
+		}
+	}
+}
+
+func TestMultiDecodePipeBuffer(t *testing.T) {
+	src := make([]byte, tDecBigLen)
+	for i := range src {
+		src[i] = byte(i * 11)
+	}
+	encoded := uuencode.EncodeToString(src) + "junk\n" +
+		uuencode.EncodeToString(src[:100])
+	d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithPipeBuffer(64 << 10))
+	rs := make(chan io.ReadCloser, 2)
+	go func() {
+		for r := range ch {
+			rs <- r
+		}
+		close(rs)
+	}()
+	// nobody reads the decoded contents until Transform finishes.
+	out, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(encoded), d))
+	d.Close()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if string(out) != "junk\n" {
+		t.Error("Got: ", string(out), " Expecting: junk")
+	}
+	wants := [][]byte{src, src[:100]}
+	var i int
+	for r := range rs {
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(i, "err at read all:", err)
+		}
+		if !bytes.Equal(got, wants[i]) {
+			t.Error(i, "decoded contents mismatch")
+		}
+		i++
+	}
+	if i != len(wants) {
+		t.Error("Got parts: ", i, " Expecting: ", len(wants))
+	}
+}
+
+func TestMultiDecodePipeBufferSmall(t *testing.T) {
+	src := make([]byte, tDecBigLen)
+	for i := range src {
+		src[i] = byte(i * 13)
+	}
+	d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithPipeBuffer(7))
+	done := make(chan []byte)
+	go func() {
+		for r := range ch {
+			b, _ := ioutil.ReadAll(r)
+			done <- b
+		}
+	}()
+	if _, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(uuencode.EncodeToString(src)), d)); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	got := <-done
+	d.Close()
+	if !bytes.Equal(got, src) {
+		t.Error("decoded contents mismatch")
+	}
+}
+
+func TestMultiDecodePipeBufferCancel(t *testing.T) {
+	src := make([]byte, tDecBigLen)
+	d, cancel, ch := uuencode.NewMultiDecodeWith(uuencode.WithPipeBuffer(16))
+	go func() {
+		for range ch {
+			// never read, so Transform blocks on the full buffer.
+			cancel()
+		}
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(uuencode.EncodeToString(src)), d))
+	d.Close()
+	if !errors.Is(err, uuencode.ErrUuCancel) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrUuCancel)
+	}
+}
@@ -0,0 +1,336 @@
+package uuencode
+
+import (
+	"bufio"
+	"bytes"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// PartReader iterates the uuencoded contents of a stream sequentially, like
+// mime/multipart.Reader. It does not start any goroutine.
+type PartReader struct {
+	r   *bufio.Reader
+	dec uuBodyDec
+	cur *Part
+	err error
+	// line, offset and parts are the total lines, bytes and begin lines read,
+	// used for error reporting.
+	line   int
+	offset int64
+	parts  int
+	// text collects the skipped bytes if it is not nil.
+	text *bytes.Buffer
+	// tab reads the translation table for the next uuencoded content.
+	tab tableReader
+}
+
+// Part is one uuencoded content read by PartReader. Read returns the decoded
+// bytes and io.EOF after the end marker line. The trailer lines after the end
+// marker line are verified, *ChecksumError is returned instead of io.EOF if
+// the crc32 line does not match.
+type Part struct {
+	// Header is the parsed begin line of the uuencoded content.
+	Header Header
+	pr     *PartReader
+	buf    []byte
+	out    []byte
+	lbuf   []byte
+	err    error
+	// crc and size are of the decoded bytes for the trailer lines.
+	crc  uint32
+	size int64
+}
+
+// NewPartReader returns PartReader that reads the uuencoded contents from r.
+// Any bytes that do not belong to uuencoded contents are skipped.
+func NewPartReader(r io.Reader) *PartReader {
+	return &PartReader{r: bufio.NewReader(r)}
+}
+
+// NextPart returns the next uuencoded content. The unread bytes of the previous
+// Part are discarded. It returns io.EOF when there is no more uuencoded
+// content.
+func (pr *PartReader) NextPart() (*Part, error) {
+	if pr.cur != nil {
+		if _, err := io.Copy(ioutil.Discard, pr.cur); err != nil {
+			pr.err = err
+		}
+		pr.cur = nil
+	}
+	for pr.err == nil {
+		b, err := pr.readLine()
+		if err == bufio.ErrBufferFull {
+			if bytes.HasPrefix(b, []byte(uuBeginMarker)) {
+				pr.err = pr.posError(&HeaderError{
+					Raw: string(b[:maxUuDecLine]), Err: ErrBadLen})
+				break
+			}
+			// skip the rest of too long line that is not uuencoded.
+			pr.keepText(b)
+			for err == bufio.ErrBufferFull {
+				b, err = pr.readLine()
+				pr.keepText(b)
+			}
+			pr.err = err
+			continue
+		}
+		if pr.tab.isTableLine(b) {
+			if terr := pr.tab.read(b); terr != nil {
+				pr.err = pr.posError(terr)
+				break
+			}
+			pr.err = err
+			continue
+		}
+		if bytes.HasPrefix(b, []byte(uuBeginMarker)) {
+			if err != nil {
+				// begin line without any body.
+				pr.err = pr.posError(&MissingEndError{})
+				break
+			}
+			b = bytes.TrimSuffix(bytes.TrimSuffix(b, []byte{'\n'}),
+				[]byte{'\r'})
+			pr.parts++
+			pr.dec.alpha = pr.tab.take()
+			pr.cur = &Part{Header: parseHeader(b), pr: pr}
+			return pr.cur, nil
+		}
+		pr.keepText(b)
+		pr.err = err
+	}
+	return nil, pr.err
+}
+
+// keepText collects skipped b if needed.
+func (pr *PartReader) keepText(b []byte) {
+	if pr.text != nil {
+		pr.text.Write(b)
+	}
+}
+
+// readLine reads one line including the end of line character. The error is
+// io.EOF if the line is the last one without end of line character, or
+// bufio.ErrBufferFull if the line is too long and only its beginning is read.
+// The line is only valid until the next read. The leading UTF-8 BOM of the
+// input is dropped.
+func (pr *PartReader) readLine() ([]byte, error) {
+	b, err := pr.r.ReadSlice('\n')
+	if err != bufio.ErrBufferFull {
+		pr.line++
+	}
+	pr.offset += int64(len(b))
+	if pr.offset == int64(len(b)) {
+		// the byte order mark is not part of the first line.
+		b = bytes.TrimPrefix(b, []byte(utf8BOM))
+	}
+	return b, err
+}
+
+// posError wraps err as *DecodeError for the last read line.
+func (pr *PartReader) posError(err error) error {
+	return &DecodeError{Line: pr.line, Offset: pr.offset, Part: pr.parts - 1,
+		Err: err}
+}
+
+// Read implements io.Reader that returns the decoded bytes of the uuencoded
+// content.
+func (p *Part) Read(b []byte) (int, error) {
+	for len(p.out) == 0 && p.err == nil {
+		p.decodeLine()
+	}
+	if len(p.out) > 0 {
+		n := copy(b, p.out)
+		p.out = p.out[n:]
+		return n, nil
+	}
+	return 0, p.err
+}
+
+// decodeLine decodes the next body line into out or sets err.
+func (p *Part) decodeLine() {
+	pr := p.pr
+	if pr.err != nil {
+		p.err = pr.err
+		return
+	}
+	startLine, start := pr.line, pr.offset
+	line, err := pr.readLine()
+	if err == bufio.ErrBufferFull {
+		pr.line, pr.offset = startLine+1, start
+		p.err = pr.posError(ErrBadLen)
+		pr.err = p.err
+		return
+	} else if err == nil && len(line) > 0 && pr.dec.isZero(line[0]) {
+		// the end marker line is needed to finish the content.
+		p.lbuf = append(p.lbuf[:0], line...)
+		var next []byte
+		next, err = pr.readLine()
+		if err == bufio.ErrBufferFull {
+			// too long line is not the end marker, decode it as the last
+			// line to report the missing end marker.
+			err = io.EOF
+		}
+		line = append(p.lbuf, next...)
+		p.lbuf = line
+	}
+	if err != nil && err != io.EOF {
+		pr.err, p.err = err, err
+		return
+	} else if len(line) == 0 {
+		p.err = pr.posError(&MissingEndError{})
+		pr.err = p.err
+		return
+	}
+	if cap(p.buf) < len(line) {
+		p.buf = make([]byte, len(line))
+	}
+	n, m, derr := pr.dec.Transform(p.buf[:len(line)], line, err == io.EOF)
+	p.out = p.buf[:n]
+	p.crc = crc32.Update(p.crc, crc32.IEEETable, p.out)
+	p.size += int64(n)
+	if derr == errFoundEOF {
+		p.err = io.EOF
+		if terr := pr.checkTrailer(p.crc, p.size); terr != nil {
+			p.err = terr
+			pr.err = p.err
+		}
+	} else if derr != nil {
+		// report the line that starts right after the consumed bytes.
+		pr.line = startLine + bytes.Count(line[:m], []byte{'\n'}) + 1
+		pr.offset = start + int64(m)
+		p.err = pr.posError(derr)
+		pr.err = p.err
+	}
+}
+
+// checkTrailer consumes the trailer lines following the end marker line of the
+// uuencoded content whose decoded bytes have CRC-32 crc and length size, and
+// verifies them.
+func (pr *PartReader) checkTrailer(crc uint32, size int64) error {
+	var sizeSeen bool
+	for {
+		kind, err := checkTrailerLine(pr.peekLine(), crc, size, sizeSeen)
+		if kind == trailerNone && err == nil {
+			return nil
+		}
+		start := pr.offset
+		b, _ := pr.readLine()
+		if err != nil {
+			// report the start of the trailer line.
+			pr.offset = start
+			return pr.posError(err)
+		} else if kind == trailerText {
+			pr.keepText(b)
+		} else if kind == trailerLast {
+			return nil
+		}
+		sizeSeen = true
+	}
+}
+
+// peekLine returns the next line without reading it, or nil if it is longer
+// than a trailer line.
+func (pr *PartReader) peekLine() []byte {
+	for {
+		b, _ := pr.r.Peek(pr.r.Buffered())
+		if n := bytes.IndexByte(b, '\n'); n >= 0 {
+			return b[:n+1]
+		} else if len(b) > maxTrailerLine {
+			return nil
+		}
+		if _, err := pr.r.Peek(len(b) + 1); err != nil {
+			// the last line without end of line character.
+			b, _ = pr.r.Peek(pr.r.Buffered())
+			return b
+		}
+	}
+}
+
+// MultiPart is one uuencoded content sent by the chan of NewMultiDecodeParts.
+// The metadata travels with its reader, so it is safe to use in the consumer
+// goroutine.
+type MultiPart struct {
+	// Header is the parsed begin line of the uuencoded content.
+	Header Header
+	// Offset is the byte offset of the begin line in the input.
+	Offset int64
+	// ReadCloser returns the decoded bytes of the uuencoded content.
+	io.ReadCloser
+	// Err is the error found on the begin line, eg: *HeaderError wrapping
+	// ErrBadMode for invalid permission. The content is still decoded.
+	Err error
+	// sum is the digest of WithDigest.
+	sum *partDigest
+}
+
+// headerReader is io.ReadCloser sent by the chan of NewMultiDecode with the
+// begin line of its uuencoded content.
+type headerReader struct {
+	io.ReadCloser
+	header Header
+	sum    *partDigest
+}
+
+// PartHeader returns the parsed begin line of r received from the chan of
+// NewMultiDecode, NewMultiDecodeWith or NewMultiDecodeContext. The begin line
+// travels with r, so unlike Decode.Header it is safe to use in the consumer
+// goroutine. ok is false if r is not received from such chan.
+func PartHeader(r io.Reader) (h Header, ok bool) {
+	if hr, ok := r.(*headerReader); ok {
+		return hr.header, true
+	}
+	return Header{}, false
+}
+
+// NewMultiDecodeParts is like NewMultiDecodeWith but the chan yields MultiPart
+// that carries the begin line of each uuencoded content.
+func NewMultiDecodeParts(opts ...DecodeOption) (*Decode, func(),
+	<-chan MultiPart) {
+	d, cancel, _ := NewMultiDecodeWith(opts...)
+	d.partCh = make(chan MultiPart)
+	return d, cancel, d.partCh
+}
+
+// ResetMultiParts is like ResetMulti but for Decode of NewMultiDecodeParts.
+func (d *Decode) ResetMultiParts() (func(), <-chan MultiPart) {
+	cancel := d.resetMulti()
+	d.partCh = make(chan MultiPart)
+	return cancel, d.partCh
+}
+
+// send sends the reader of the current uuencoded content to the consumer. It
+// returns false if the decoding is canceled.
+func (d *Decode) send(r io.ReadCloser) bool {
+	if d.partCh == nil {
+		select {
+		case d.ch <- &headerReader{ReadCloser: r, header: d.header,
+			sum: d.partSum}:
+			return true
+		case <-d.cancel:
+			return false
+		}
+	}
+	p := MultiPart{Header: d.header, Offset: d.headerOff, ReadCloser: r,
+		sum: d.partSum}
+	if _, err := parseMode(modeField(d.header.Raw)); err != nil {
+		p.Err = &HeaderError{Raw: d.header.Raw, Err: err}
+	}
+	select {
+	case d.partCh <- p:
+		return true
+	case <-d.cancel:
+		return false
+	}
+}
+
+// modeField returns the permission field of the begin line raw.
+func modeField(raw string) string {
+	as := strings.Split(raw, " ")
+	if len(as) > 1 {
+		return as[1]
+	}
+	return ""
+}
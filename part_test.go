@@ -0,0 +1,197 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestPartReader(t *testing.T) {
+	big := make([]byte, tDecBigLen)
+	for i := range big {
+		big[i] = byte(i * 5)
+	}
+	src := "junk\nbegin 644 pp.txt\r\n322!L;W9E('EO=2!F;W)E=F5R+@``\r\n`\r\n" +
+		"end\r\nmore junk\n" + uuencode.EncodeToString(big) +
+		"begin 600 empty\n`\nend"
+	pr := uuencode.NewPartReader(bytes.NewBufferString(src))
+	wants := []struct {
+		name string
+		data string
+	}{
+		{"pp.txt", "I love you forever."},
+		{"filename", string(big)},
+		{"empty", ""},
+	}
+	for i, want := range wants {
+		p, err := pr.NextPart()
+		if err != nil {
+			t.Fatal("part", i, "err:", err)
+		}
+		if p.Header.Name != want.name {
+			t.Error("part", i, "got name:", p.Header.Name, "want:", want.name)
+		}
+		got, err := ioutil.ReadAll(p)
+		if err != nil {
+			t.Fatal("part", i, "read err:", err)
+		}
+		if diff := pretty.Compare(string(got), want.data); diff != "" {
+			t.Errorf("part %d Diff: %s", i, diff)
+		}
+	}
+	if p, err := pr.NextPart(); err != io.EOF {
+		t.Error("Got: ", p, err, " Expecting: ", io.EOF)
+	}
+}
+
+func TestPartReaderBOM(t *testing.T) {
+	pr := uuencode.NewPartReader(bytes.NewBufferString(
+		"\xef\xbb\xbfbegin 644 a.txt\n#86)C\n`\nend\n"))
+	p, err := pr.NextPart()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if p.Header.Name != "a.txt" {
+		t.Error("Got name:", p.Header.Name, "Expecting: a.txt")
+	}
+	got, err := ioutil.ReadAll(p)
+	if err != nil {
+		t.Fatal("read err:", err)
+	}
+	if diff := pretty.Compare(string(got), "abc"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestPartReaderSkipUnread(t *testing.T) {
+	src := uuencode.EncodeToString([]byte("first content")) +
+		uuencode.EncodeToString([]byte("second content"))
+	pr := uuencode.NewPartReader(bytes.NewBufferString(src))
+	if _, err := pr.NextPart(); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	p, err := pr.NextPart()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	got, err := ioutil.ReadAll(p)
+	if err != nil || string(got) != "second content" {
+		t.Error("Got: ", string(got), err)
+	}
+}
+
+func TestPartReaderFail(t *testing.T) {
+	tsts := []struct {
+		src  string
+		line int
+	}{
+		{"begin 644 a\n322!L;W9E\n`\nend\n", 2},
+		{"begin 644 a\n322!L;W9E('EO=2!F;W)E=F5R+@``\n", 3},
+		{"begin 644 a\n322!L;W9E('EO=2!F;W)E=F5R+@``\n`\nfoo\n", 4},
+	}
+	for i, tst := range tsts {
+		pr := uuencode.NewPartReader(bytes.NewBufferString(tst.src))
+		p, err := pr.NextPart()
+		if err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		_, err = ioutil.ReadAll(p)
+		var de *uuencode.DecodeError
+		if !errors.As(err, &de) || !errors.Is(err, uuencode.ErrBadUUDec) {
+			t.Error(i, "Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
+			continue
+		}
+		if de.Line != tst.line {
+			t.Error(i, "Got line: ", de.Line, " Expecting: ", tst.line)
+		}
+		if _, nerr := pr.NextPart(); nerr != err {
+			t.Error(i, "NextPart got: ", nerr, " Expecting: ", err)
+		}
+	}
+}
+
+func TestMultiDecodeParts(t *testing.T) {
+	src := "begin 644 a.txt\n#86)C\n`\nend\njunk\n" +
+		"begin 9x9 b.txt\n#9&5F\n`\nend\n"
+	d, _, ch := uuencode.NewMultiDecodeParts()
+	type result struct {
+		name, data string
+		err        error
+	}
+	done := make(chan []result)
+	go func() {
+		var rs []result
+		for p := range ch {
+			b, err := ioutil.ReadAll(p)
+			if err != nil {
+				b = []byte(err.Error())
+			}
+			rs = append(rs, result{p.Header.Name, string(b), p.Err})
+		}
+		done <- rs
+	}()
+	out, err := ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(src), d))
+	d.Close()
+	rs := <-done
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if string(out) != "junk\n" {
+		t.Error("Got: ", string(out), " Expecting: junk")
+	}
+	if len(rs) != 2 {
+		t.Fatal("Got parts: ", rs)
+	}
+	if rs[0].name != "a.txt" || rs[0].data != "abc" || rs[0].err != nil {
+		t.Error("Got: ", rs[0])
+	}
+	if rs[1].name != "b.txt" || rs[1].data != "def" ||
+		!errors.Is(rs[1].err, uuencode.ErrBadMode) {
+		t.Error("Got: ", rs[1])
+	}
+	// the closed Decode is reused for the next input.
+	_, ch = d.ResetMultiParts()
+	go func() {
+		for p := range ch {
+			b, _ := ioutil.ReadAll(p)
+			done <- []result{{p.Header.Name, string(b), p.Err}}
+		}
+	}()
+	_, err = ioutil.ReadAll(transform.NewReader(
+		bytes.NewBufferString(src[:30]), d))
+	d.Close()
+	if rs = <-done; err != nil || len(rs) != 1 || rs[0].data != "abc" {
+		t.Error("Got: ", rs, err)
+	}
+}
+
+func TestPartReaderLongLine(t *testing.T) {
+	junk := bytes.Repeat([]byte("junk "), 10000)
+	src := string(junk) + "\n" + uuencode.EncodeToString([]byte("abc"))
+	pr := uuencode.NewPartReader(bytes.NewBufferString(src))
+	p, err := pr.NextPart()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if got, err := ioutil.ReadAll(p); err != nil || string(got) != "abc" {
+		t.Error("Got: ", string(got), err)
+	}
+	src = "begin 644 a\n" + string(junk) + "\n`\nend\n"
+	pr = uuencode.NewPartReader(bytes.NewBufferString(src))
+	if p, err = pr.NextPart(); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	_, err = ioutil.ReadAll(p)
+	var de *uuencode.DecodeError
+	if !errors.As(err, &de) || !errors.Is(err, uuencode.ErrBadLen) ||
+		de.Line != 2 {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadLen)
+	}
+}
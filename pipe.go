@@ -0,0 +1,139 @@
+package uuencode
+
+import (
+	"io"
+	"sync"
+)
+
+// pipeReader is the reading half of the pipe between decoder and consumer of
+// multiple uuencoded contents decoding.
+type pipeReader interface {
+	io.ReadCloser
+	CloseWithError(err error) error
+}
+
+// pipeWriter is the writing half of the pipe between decoder and consumer of
+// multiple uuencoded contents decoding.
+type pipeWriter interface {
+	io.WriteCloser
+	CloseWithError(err error) error
+}
+
+// newPipe returns io.Pipe or a buffered pipe if WithPipeBuffer is set.
+func (d *Decode) newPipe() (pipeReader, pipeWriter) {
+	if d.pipeBuf > 0 {
+		p := newBufPipe(d.pipeBuf)
+		return &bufPipeReader{p}, &bufPipeWriter{p}
+	}
+	return io.Pipe()
+}
+
+// bufPipe is like io.Pipe but Write only blocks when size bytes are not read
+// yet.
+type bufPipe struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  []byte
+	size int
+	// rerr is returned by Read after buf is drained. werr is returned by
+	// Write.
+	rerr, werr error
+}
+
+func newBufPipe(size int) *bufPipe {
+	p := &bufPipe{buf: make([]byte, 0, size), size: size}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+func (p *bufPipe) read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.buf) == 0 || p.werr != nil {
+		if p.werr != nil {
+			// read half is closed.
+			return 0, io.ErrClosedPipe
+		} else if p.rerr != nil {
+			return 0, p.rerr
+		}
+		p.cond.Wait()
+	}
+	n := copy(b, p.buf)
+	p.buf = p.buf[:copy(p.buf, p.buf[n:])]
+	p.cond.Broadcast()
+	return n, nil
+}
+
+func (p *bufPipe) write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var n int
+	for len(b) > 0 {
+		if p.werr != nil {
+			return n, p.werr
+		} else if p.rerr != nil {
+			return n, io.ErrClosedPipe
+		}
+		space := p.size - len(p.buf)
+		if space == 0 {
+			p.cond.Wait()
+			continue
+		}
+		if space > len(b) {
+			space = len(b)
+		}
+		p.buf = append(p.buf, b[:space]...)
+		b = b[space:]
+		n += space
+		p.cond.Broadcast()
+	}
+	return n, nil
+}
+
+// closeRead makes Write return err.
+func (p *bufPipe) closeRead(err error) {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	p.mu.Lock()
+	if p.werr == nil {
+		p.werr = err
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+// closeWrite makes Read return err after the buffered bytes are read.
+func (p *bufPipe) closeWrite(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+	p.mu.Lock()
+	if p.rerr == nil {
+		p.rerr = err
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+type bufPipeReader struct{ p *bufPipe }
+
+func (r *bufPipeReader) Read(b []byte) (int, error) { return r.p.read(b) }
+
+func (r *bufPipeReader) Close() error { return r.CloseWithError(nil) }
+
+func (r *bufPipeReader) CloseWithError(err error) error {
+	r.p.closeRead(err)
+	return nil
+}
+
+type bufPipeWriter struct{ p *bufPipe }
+
+func (w *bufPipeWriter) Write(b []byte) (int, error) { return w.p.write(b) }
+
+func (w *bufPipeWriter) Close() error { return w.CloseWithError(nil) }
+
+func (w *bufPipeWriter) CloseWithError(err error) error {
+	w.p.closeWrite(err)
+	return nil
+}
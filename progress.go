@@ -0,0 +1,28 @@
+package uuencode
+
+// Progress reports the progress of encoding or decoding.
+type Progress struct {
+	// In is the total bytes consumed.
+	In int64
+	// Out is the total bytes outputted. For multiple uuencoded contents
+	// decoding, it includes the decoded bytes sent to the io.ReadCloser chan.
+	Out int64
+	// Part is the index (starts from 0) of the uuencoded content being
+	// processed. It has the same meaning as in DecodeError and is always zero
+	// for encoding.
+	Part int
+}
+
+// OnProgress sets f to be called after every Transform call that consumes or
+// outputs bytes. f runs in the goroutine calling Transform and should return
+// quickly. The counts restart from zero after Reset.
+func (e *Encode) OnProgress(f func(Progress)) {
+	e.onProgress = f
+}
+
+// OnProgress sets f to be called after every Transform call that consumes or
+// outputs bytes. f runs in the goroutine calling Transform and should return
+// quickly. The counts restart from zero after Reset.
+func (d *Decode) OnProgress(f func(Progress)) {
+	d.onProgress = f
+}
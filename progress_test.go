@@ -0,0 +1,74 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestEncodeDecodeProgress(t *testing.T) {
+	src := make([]byte, tEncDecSize)
+	for i := range src {
+		src[i] = byte(i + 1)
+	}
+	var last uuencode.Progress
+	var calls int
+	e := uuencode.NewEncodeWith()
+	e.OnProgress(func(p uuencode.Progress) {
+		if p.In < last.In || p.Out < last.Out {
+			t.Errorf("progress goes backward from %v to %v", last, p)
+		}
+		last = p
+		calls++
+	})
+	encoded, err := ioutil.ReadAll(transform.NewReader(bytes.NewReader(src), e))
+	if err != nil {
+		t.Fatal("err at encoding read all:", err)
+	}
+	if calls == 0 || last.In != int64(len(src)) ||
+		last.Out != int64(len(encoded)) {
+		t.Errorf("unexpected last encode progress %v", last)
+	}
+	last, calls = uuencode.Progress{}, 0
+	d := uuencode.NewDecode()
+	d.OnProgress(func(p uuencode.Progress) {
+		last = p
+		calls++
+	})
+	_, err = ioutil.ReadAll(transform.NewReader(bytes.NewReader(encoded), d))
+	if err != nil {
+		t.Fatal("err at decoding read all:", err)
+	}
+	if calls == 0 || last.In != int64(len(encoded)) ||
+		last.Out != int64(len(src)) || last.Part != 0 {
+		t.Errorf("unexpected last decode progress %v", last)
+	}
+}
+
+func TestMultiDecodeProgress(t *testing.T) {
+	one := uuencode.EncodeToString([]byte("I love you forever."))
+	in := "text\n" + one + one
+	var last uuencode.Progress
+	d, _, ch := uuencode.NewMultiDecode()
+	d.OnProgress(func(p uuencode.Progress) {
+		last = p
+	})
+	go func() {
+		for r := range ch {
+			io.Copy(ioutil.Discard, r)
+		}
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(in), d))
+	d.Close()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	want := uuencode.Progress{In: int64(len(in)), Out: 5 + 2*19, Part: 2}
+	if last != want {
+		t.Errorf("Want: %v\n Got: %v", want, last)
+	}
+}
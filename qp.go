@@ -0,0 +1,149 @@
+package uuencode
+
+import (
+	"bytes"
+
+	"golang.org/x/text/transform"
+)
+
+const (
+	// maxQPJoin is the maximum number of physical lines joined by soft line
+	// breaks into one uuencoded line.
+	maxQPJoin = 3
+	// maxQPLine is the maximum length of line QPRepair waits for the end of
+	// line. Longer line can not be an uuencoded line and is passed through.
+	maxQPLine = 1024
+)
+
+// QPRepair implements golang/x/text/transform.Transformer that reverses the
+// quoted-printable encoding applied to uuencoded body lines by some mail
+// relays, ie: "=3D" for "=", "=20" for trailing space and soft line breaks.
+// Chain it before Decode:
+//
+//	transform.Chain(uuencode.NewQPRepair(), uuencode.NewDecode())
+//
+// Since "=" is a valid uuencode character, only the lines that are not valid
+// uuencoded lines as is but become valid once quoted-printable decoded are
+// repaired. Any other bytes are passed through as is.
+type QPRepair struct {
+	// Repaired is the number of repaired uuencoded lines.
+	Repaired int
+	// buf holds the joined line and dec holds the decoded line.
+	buf, dec []byte
+}
+
+// NewQPRepair returns QPRepair.
+func NewQPRepair() *QPRepair {
+	return &QPRepair{}
+}
+
+// Reset implements transform.Transformer interface.
+func (q *QPRepair) Reset() {
+	q.Repaired = 0
+}
+
+// Transform implements transform.Transformer interface.
+func (q *QPRepair) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	var nDst, nSrc int
+	for nSrc < len(src) {
+		rest := src[nSrc:]
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 && !atEOF && len(rest) < maxQPLine {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		line := rest
+		if i >= 0 {
+			line = rest[:i+1]
+		}
+		out, n, ok := q.repair(rest, atEOF)
+		if !ok {
+			return nDst, nSrc, transform.ErrShortSrc
+		} else if n == 0 {
+			// not quoted-printable damaged.
+			out, n = line, len(line)
+		} else {
+			q.Repaired++
+		}
+		if len(dst[nDst:]) < len(out) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], out)
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+// repair returns the repaired uuencoded line starting at src and the length
+// of src it replaces. n is zero if the line is not repaired. ok is false if src
+// does not hold enough lines to decide.
+func (q *QPRepair) repair(src []byte, atEOF bool) (out []byte, n int,
+	ok bool) {
+	line, eol := splitEOL(src)
+	if bytes.IndexByte(line, '=') < 0 || validBodyLine(line) {
+		return nil, 0, true
+	}
+	q.buf = q.buf[:0]
+	for i := 1; ; i++ {
+		n += len(line) + len(eol)
+		soft := len(eol) > 0 && bytes.HasSuffix(line, []byte{'='})
+		if soft {
+			line = line[:len(line)-1]
+		}
+		q.buf = append(q.buf, line...)
+		if !soft || i == maxQPJoin {
+			break
+		} else if bytes.IndexByte(src[n:], '\n') < 0 && !atEOF {
+			return nil, 0, false
+		}
+		line, eol = splitEOL(src[n:])
+	}
+	q.dec, ok = qpDecode(q.dec[:0], q.buf)
+	if !ok || !validBodyLine(q.dec) {
+		return nil, 0, true
+	}
+	return append(q.dec, eol...), n, true
+}
+
+// splitEOL returns the first line of b without the end of line characters and
+// the end of line characters.
+func splitEOL(b []byte) (line, eol []byte) {
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return b, nil
+	} else if i > 0 && b[i-1] == '\r' {
+		return b[:i-1], b[i-1 : i+1]
+	}
+	return b[:i], b[i : i+1]
+}
+
+// qpDecode appends the quoted-printable decoded b to dst. ok is false if b has
+// invalid escape sequence.
+func qpDecode(dst, b []byte) ([]byte, bool) {
+	for i := 0; i < len(b); i++ {
+		if b[i] != '=' {
+			dst = append(dst, b[i])
+			continue
+		} else if i+2 >= len(b) {
+			return dst, false
+		}
+		h, hok := unhex(b[i+1])
+		l, lok := unhex(b[i+2])
+		if !hok || !lok {
+			return dst, false
+		}
+		dst = append(dst, h<<4|l)
+		i += 2
+	}
+	return dst, true
+}
+
+// unhex returns the value of upper case hexadecimal digit c.
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
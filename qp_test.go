@@ -0,0 +1,63 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/quotedprintable"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestQPRepair(t *testing.T) {
+	// 0x75 0xd7 0x5d is encoded as "====".
+	src := append(bytes.Repeat([]byte{0x75, 0xd7, 0x5d}, 40),
+		[]byte("I love you forever.")...)
+	enc := uuencode.NewEncodeWith(uuencode.WithGravePadding(false),
+		uuencode.WithEOL("\r\n"))
+	uu, _, err := transform.Bytes(enc, src)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	mangled := new(bytes.Buffer)
+	qw := quotedprintable.NewWriter(mangled)
+	if _, err := qw.Write(uu); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	qw.Close()
+	if bytes.Equal(mangled.Bytes(), uu) {
+		t.Fatal("quoted-printable encoding does not change the input")
+	}
+	for name, in := range map[string][]byte{"mangled": mangled.Bytes(),
+		"intact": uu} {
+		q := uuencode.NewQPRepair()
+		r := transform.NewReader(bytes.NewReader(in),
+			transform.Chain(q, uuencode.NewDecode()))
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(name, "Expecting non-error but got err:", err)
+		}
+		if !bytes.Equal(got, src) {
+			t.Error(name, "decoded bytes differ from source")
+		}
+		if name == "intact" && q.Repaired != 0 {
+			t.Error(name, "repaired", q.Repaired, "lines")
+		}
+		if name == "mangled" && q.Repaired == 0 {
+			t.Error(name, "no repaired line")
+		}
+	}
+}
+
+func TestQPRepairPassThrough(t *testing.T) {
+	in := "a=3Db\nlong line =\nnext\nbegin 644 a\n#86)C\n`\nend\n"
+	got, _, err := transform.String(uuencode.NewQPRepair(), in)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(got, in); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+package uuencode
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+// DecodeSections decodes the sections of r, eg: found by FindUuencode, with up
+// to workers goroutines concurrently, and calls f with the decoded bytes of
+// each section in the order of sections. At most workers decoded sections are
+// buffered in memory at once. Decoding failure of a section is passed to f as
+// err with the bytes decoded before the failure. Decoding stops when f returns
+// error, which is returned by DecodeSections.
+func DecodeSections(r io.ReaderAt, sections []Section, workers int,
+	f func(s Section, data []byte, err error) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	type result struct {
+		data []byte
+		err  error
+	}
+	results := make([]chan *result, len(sections))
+	for i := range results {
+		results[i] = make(chan *result, 1)
+	}
+	// sem bounds the sections being decoded or waiting to be delivered.
+	sem := make(chan struct{}, workers)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i, s := range sections {
+			select {
+			case sem <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(s Section, ch chan<- *result) {
+				res := new(result)
+				res.data, res.err = decodeSection(r, s)
+				ch <- res
+			}(s, results[i])
+		}
+	}()
+	for i, ch := range results {
+		res := <-ch
+		if err := f(sections[i], res.data, res.err); err != nil {
+			return err
+		}
+		<-sem
+	}
+	return nil
+}
+
+// decodeSection returns the decoded bytes of the first uuencoded content of
+// section s of r.
+func decodeSection(r io.ReaderAt, s Section) ([]byte, error) {
+	p, err := NewPartReader(io.NewSectionReader(r, s.Offset,
+		s.Length)).NextPart()
+	if err == io.EOF {
+		return nil, ErrBadUUDec
+	} else if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(p)
+}
@@ -0,0 +1,71 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestDecodeSections(t *testing.T) {
+	var in strings.Builder
+	var wants []string
+	for i := 0; i < 50; i++ {
+		s := strings.Repeat(fmt.Sprint(i), i*10)
+		wants = append(wants, s)
+		e := uuencode.NewEncodeWith(uuencode.WithFilename(fmt.Sprint(i)))
+		enc, _, err := transform.String(e, s)
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		fmt.Fprintf(&in, "junk %d\n%s", i, enc)
+	}
+	r := strings.NewReader(in.String())
+	sections, err := uuencode.FindUuencode(r, r.Size())
+	if err != nil || len(sections) != len(wants) {
+		t.Fatal("Got: ", len(sections), err)
+	}
+	for _, workers := range []int{0, 1, 8} {
+		var i int
+		err := uuencode.DecodeSections(r, sections, workers,
+			func(s uuencode.Section, data []byte, err error) error {
+				if err != nil {
+					t.Fatal("Expecting non-error but got err:", err)
+				}
+				if s.Header.Name != fmt.Sprint(i) ||
+					string(data) != wants[i] {
+					t.Errorf("section %d out of order or mismatch", i)
+				}
+				i++
+				return nil
+			})
+		if err != nil || i != len(wants) {
+			t.Error("Got: ", i, err)
+		}
+	}
+}
+
+func TestDecodeSectionsError(t *testing.T) {
+	in := []byte("begin 644 a\n#86)C\n`\nend\nbegin 644 b\n#86v!\n`\nend\n")
+	r := bytes.NewReader(in)
+	sections := []uuencode.Section{{Offset: 0, Length: 24},
+		{Offset: 24, Length: int64(len(in) - 24)}}
+	stop := errors.New("stop")
+	var got []error
+	err := uuencode.DecodeSections(r, append(sections, sections...), 2,
+		func(s uuencode.Section, data []byte, err error) error {
+			got = append(got, err)
+			if err != nil {
+				return stop
+			}
+			return nil
+		})
+	if err != stop || len(got) != 2 || !errors.Is(got[1],
+		uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err, got)
+	}
+}
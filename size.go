@@ -0,0 +1,48 @@
+package uuencode
+
+// EncodedLen returns the length in bytes of the output of e for n source bytes,
+// including the table section, the begin line and the end marker lines, so
+// dst of that length never gets transform.ErrShortDst for the whole source.
+func (e *Encode) EncodedLen(n int) int {
+	l := encodedLen(n, e.eol)
+	if e.bodyOnly {
+		return l
+	}
+	l += len(uuBeginMarker) + len(e.permit) + len(quoteName(e.name)) +
+		len(e.eol) + 2
+	if e.emitTable {
+		l += len(uuTableMarker) + 64 + 3*len(e.eol)
+	}
+	// the zero length line, the end marker line and the trailer lines.
+	return l + 1 + len(uuEndMarker) + 2*len(e.eol) + e.trailerLen(n)
+}
+
+// MaxLineLen returns the length in bytes of the longest line outputted by e,
+// including the end of line characters, eg: for line based writers or mail
+// line length limits.
+func (e *Encode) MaxLineLen() int {
+	l := maxEncLine + len(e.eol)
+	if e.bodyOnly {
+		return l
+	}
+	if h := len(uuBeginMarker) + len(e.permit) + len(quoteName(e.name)) +
+		len(e.eol) + 2; h > l {
+		return h
+	}
+	return l
+}
+
+// DecodedLen returns the maximum length in bytes of the output of d for n bytes
+// of input, so dst of that length never gets transform.ErrShortDst for the
+// whole input. Decoded bytes are at most 3/4 of the uuencoded lines but bytes
+// outside the uuencoded contents are outputted as is.
+func (d *Decode) DecodedLen(n int) int {
+	eol := 1
+	if d.textEOL != "" {
+		eol = len(d.textEOL)
+	}
+	if m := (n + 3) / 4 * 3 * eol; m > n {
+		return m
+	}
+	return n
+}
@@ -0,0 +1,77 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestEncodeEncodedLen(t *testing.T) {
+	encs := []*uuencode.Encode{
+		uuencode.NewEncodeWith(),
+		uuencode.NewEncodeWith(uuencode.WithEOL("\r\n"),
+			uuencode.WithFilename("a.txt"), uuencode.WithMode(0600)),
+		uuencode.NewEncodeWith(uuencode.WithAlphabet(uuencode.XXAlphabet)),
+		uuencode.NewEncodeWith(uuencode.WithTable(
+			uuencode.XXAlphabet.String())),
+		uuencode.NewBodyEncode(),
+		uuencode.NewEncodeWith(uuencode.WithSizeTrailer(true),
+			uuencode.WithCRC32Trailer(true)),
+		uuencode.NewEncodeWith(uuencode.WithEOL("\r\n"),
+			uuencode.WithSizeTrailer(true)),
+	}
+	for i, e := range encs {
+		for _, n := range []int{0, 1, 2, 3, 44, 45, 46, 90, 100, 1000} {
+			e.Reset()
+			out, _, err := transform.Bytes(e, make([]byte, n))
+			if err != nil {
+				t.Fatal(i, n, "Expecting non-error but got err:", err)
+			}
+			if l := e.EncodedLen(n); l != len(out) {
+				t.Errorf("%d %d Got: %d Expecting: %d", i, n, l, len(out))
+			}
+			// the whole source fits in dst of EncodedLen.
+			e.Reset()
+			dst := make([]byte, e.EncodedLen(n))
+			if _, _, err = e.Transform(dst, make([]byte, n), true); err != nil {
+				t.Error(i, n, "Got err:", err)
+			}
+			max := e.MaxLineLen()
+			for _, line := range strings.SplitAfter(string(out), "\n") {
+				if len(line) > max {
+					t.Errorf("%d %d Got line %q longer than %d", i, n, line,
+						max)
+				}
+			}
+		}
+	}
+	e := uuencode.NewEncodeWith(uuencode.WithFilename(strings.Repeat("a", 80)))
+	if l := e.MaxLineLen(); l != 91 {
+		t.Errorf("Got: %d Expecting: 91", l)
+	}
+}
+
+func TestDecodeDecodedLen(t *testing.T) {
+	body := uuencode.EncodeToString(bytes.Repeat([]byte("text\n"), 30))
+	ins := []string{"", "a", "text\nmore text\n", body, "text\n" + body,
+		uuencode.EncodeToString(make([]byte, 2))}
+	for _, eol := range []string{"", "\r\n"} {
+		for i, in := range ins {
+			d := uuencode.NewDecodeWith(uuencode.WithTextEOL(eol))
+			// inputs without uuencoded content fail after the output.
+			out, _, _ := transform.String(d, in)
+			if l := d.DecodedLen(len(in)); l < len(out) {
+				t.Errorf("%q %d Got: %d shorter than %d", eol, i, l, len(out))
+			}
+			d.Reset()
+			dst := make([]byte, d.DecodedLen(len(in)))
+			_, _, err := d.Transform(dst, []byte(in), true)
+			if err == transform.ErrShortDst {
+				t.Errorf("%q %d Got err: %v", eol, i, err)
+			}
+		}
+	}
+}
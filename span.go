@@ -0,0 +1,84 @@
+package uuencode
+
+import (
+	"bytes"
+
+	"golang.org/x/text/transform"
+)
+
+// Span implements golang/x/text/transform.SpanningTransformer. It returns the
+// length of the leading bytes of src that Transform would pass through as is,
+// ie: the whole lines outside of uuencoded contents and, for single decoding,
+// the bytes after the end marker line. Callers scanning mostly plain text, eg:
+// mail, can skip copying those bytes and only call Transform from the returned
+// position on transform.ErrEndOfSpan.
+//
+// Like Transform, Span consumes the spanned bytes: the decoding position and
+// progress are updated as if they were transformed.
+func (d *Decode) Span(src []byte, atEOF bool) (int, error) {
+	if d.ctx != nil && d.ctx.Err() != nil {
+		d.closePipe()
+		return 0, d.cancelErr()
+	}
+	var n int
+	var err error
+	switch {
+	case d.trailer:
+		// the line after the end marker line may be a trailer line.
+		return 0, transform.ErrEndOfSpan
+	case d.state == uuEnd:
+		n = len(src)
+	case d.state == uuStart && !d.bodyOnly:
+		n, err = d.spanStart(src, atEOF)
+	default:
+		return 0, transform.ErrEndOfSpan
+	}
+	if atEOF && err == nil && n == len(src) {
+		if err = d.endCheck(); err != nil {
+			err = d.posError(src, err)
+		}
+	}
+	d.line += bytes.Count(src[:n], []byte{'\n'})
+	d.offset += int64(n)
+	d.out += int64(n)
+	if d.onProgress != nil && n > 0 {
+		_, _, part := d.position(nil)
+		d.onProgress(Progress{In: d.offset, Out: d.out, Part: part})
+	}
+	return n, err
+}
+
+// spanStart returns the length of the leading lines of src that are neither
+// begin lines nor part of the table section.
+func (d *Decode) spanStart(src []byte, atEOF bool) (int, error) {
+	var n int
+	for n < len(src) {
+		i := bytes.IndexByte(src[n:], '\n')
+		line := src[n:]
+		if i >= 0 {
+			line = src[n : n+i+1]
+		}
+		bom := d.bomLen(line, n)
+		if bom > 0 && d.stripBOM {
+			return n, transform.ErrEndOfSpan
+		}
+		line = line[bom:]
+		if d.tab.isTableLine(line) ||
+			bytes.HasPrefix(line, []byte(uuBeginMarker)) {
+			return n, transform.ErrEndOfSpan
+		}
+		if i < 0 {
+			if atEOF {
+				d.checkCR(src[:n], line)
+				return len(src), nil
+			} else if n == 0 && len(src) >= defaultMaxBuff {
+				// too long line, let Transform report it.
+				return n, transform.ErrEndOfSpan
+			}
+			return n, transform.ErrShortSrc
+		}
+		d.checkCR(src[:n], line[:len(line)-1])
+		n += i + 1
+	}
+	return n, nil
+}
@@ -0,0 +1,128 @@
+package uuencode_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+// spanString transforms s with d using Span for the passthrough bytes.
+func spanString(d *uuencode.Decode, s string) (string, error) {
+	src := []byte(s)
+	dst := make([]byte, len(src)+64)
+	var out []byte
+	for {
+		n, err := d.Span(src, true)
+		out = append(out, src[:n]...)
+		src = src[n:]
+		if err != transform.ErrEndOfSpan {
+			return string(out), err
+		}
+		nDst, nSrc, err := d.Transform(dst, src, true)
+		out = append(out, dst[:nDst]...)
+		src = src[nSrc:]
+		if err != nil || len(src) == 0 {
+			return string(out), err
+		}
+	}
+}
+
+func TestDecodeSpan(t *testing.T) {
+	const body = "begin 644 a.txt\n#86)C\n`\nend\n"
+	tests := []struct {
+		in   string
+		span int
+	}{
+		{in: "hello\nworld\n" + body + "tail\n", span: 12},
+		{in: body + "tail", span: 0},
+		{in: "table\n" + body, span: 0},
+		{in: "x\ntable\n" + body, span: 2},
+		{in: "x\nbegin", span: 2},
+	}
+	for _, tt := range tests {
+		n, err := uuencode.NewDecode().Span([]byte(tt.in), true)
+		if err != transform.ErrEndOfSpan {
+			t.Errorf("%q: Expecting ErrEndOfSpan but got: %v", tt.in, err)
+		}
+		if n != tt.span {
+			t.Errorf("%q: span %d, want %d", tt.in, n, tt.span)
+		}
+		want, _, wantErr := transform.String(uuencode.NewDecode(), tt.in)
+		got, err := spanString(uuencode.NewDecode(), tt.in)
+		if diff := pretty.Compare(got, want); diff != "" {
+			t.Errorf("%q: Diff: %s", tt.in, diff)
+		}
+		if diff := pretty.Compare(err, wantErr); diff != "" {
+			t.Errorf("%q: error Diff: %s", tt.in, diff)
+		}
+	}
+}
+
+func TestDecodeSpanShortSrc(t *testing.T) {
+	d := uuencode.NewDecode()
+	n, err := d.Span([]byte("hello\nwor"), false)
+	if n != 6 || err != transform.ErrShortSrc {
+		t.Errorf("got %d, %v; want 6, ErrShortSrc", n, err)
+	}
+}
+
+func TestDecodeSpanNoContent(t *testing.T) {
+	d := uuencode.NewDecode()
+	n, err := d.Span([]byte("hello\n"), true)
+	if n != 6 {
+		t.Errorf("span %d, want 6", n)
+	}
+	var derr *uuencode.DecodeError
+	if !errors.As(err, &derr) || !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Fatal("Expecting *DecodeError of ErrBadUUDec but got:", err)
+	}
+	if derr.Line != 2 {
+		t.Errorf("error at line %d, want 2", derr.Line)
+	}
+}
+
+func TestDecodeSpanPosition(t *testing.T) {
+	const in = "a\nb\nbegin 644 a\n#8\n`\nend\n"
+	d := uuencode.NewDecode()
+	_, err := spanString(d, in)
+	var derr *uuencode.DecodeError
+	if !errors.As(err, &derr) {
+		t.Fatal("Expecting *DecodeError but got:", err)
+	}
+	if derr.Line != 4 || derr.Offset != 16 {
+		t.Errorf("error at line %d offset %d, want line 4 offset 16",
+			derr.Line, derr.Offset)
+	}
+}
+
+func TestDecodeSpanAfterEnd(t *testing.T) {
+	d := uuencode.NewDecode()
+	got, err := spanString(d, "begin 644 a\n#86)C\n`\nend\nrest\nbegin x\n")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(got, "abcrest\nbegin x\n"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestDecodeSpanBOM(t *testing.T) {
+	const in = "\xef\xbb\xbftext\nbegin 644 a\n#86)C\n`\nend\n"
+	for _, strip := range []bool{false, true} {
+		d := uuencode.NewDecodeWith(uuencode.WithStripBOM(strip))
+		got, err := spanString(d, in)
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		want := "\xef\xbb\xbftext\nabc"
+		if strip {
+			want = "text\nabc"
+		}
+		if diff := pretty.Compare(got, want); diff != "" {
+			t.Errorf("strip %v Diff: %s", strip, diff)
+		}
+	}
+}
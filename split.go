@@ -0,0 +1,114 @@
+package uuencode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"golang.org/x/text/transform"
+)
+
+// Segment is an item yielded by Splitter, either TextSegment or *Part.
+type Segment interface {
+	segment()
+}
+
+// TextSegment is the plain text between uuencoded contents, including its end
+// of line characters.
+type TextSegment struct {
+	Text []byte
+}
+
+func (TextSegment) segment() {}
+
+func (*Part) segment() {}
+
+// Splitter separates the plain text from the uuencoded contents of a stream,
+// preserving their order, eg: the mail body paragraphs around attachments.
+type Splitter struct {
+	pr   *PartReader
+	text bytes.Buffer
+	// next is the part or error found after the pending text.
+	next    *Part
+	nextErr error
+	pending bool
+}
+
+// NewSplitter returns Splitter that reads from r.
+func NewSplitter(r io.Reader) *Splitter {
+	s := &Splitter{pr: NewPartReader(r)}
+	s.pr.text = &s.text
+	return s
+}
+
+// Next returns the next Segment in input order: TextSegment for the plain text
+// and *Part for the uuencoded content. Consecutive plain text lines are merged
+// into one TextSegment. The unread bytes of the previous *Part are discarded.
+// It returns io.EOF at the end of input.
+func (s *Splitter) Next() (Segment, error) {
+	if !s.pending {
+		s.next, s.nextErr = s.pr.NextPart()
+		s.pending = true
+	}
+	if s.text.Len() > 0 {
+		t := TextSegment{Text: make([]byte, s.text.Len())}
+		copy(t.Text, s.text.Bytes())
+		s.text.Reset()
+		return t, nil
+	}
+	s.pending = false
+	if s.nextErr != nil {
+		return nil, s.nextErr
+	}
+	return s.next, nil
+}
+
+// SplitEncode uuencodes src with e and splits the result into parts of at most
+// maxBytes each, for posting through systems with message size caps. Lines are
+// never broken. If banner is true, each part starts with a "section i of n of
+// file name" line, which the decoder in lenient mode and Assembler skip. nil e
+// uses the same setting as Uue.NewEncoder.
+func SplitEncode(src []byte, maxBytes int, banner bool, e *Encode) ([][]byte,
+	error) {
+	if e == nil {
+		e = NewEncode(true, "\n")
+	}
+	enc, _, err := transform.Bytes(e, src)
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.SplitAfter(enc, []byte(e.eol))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	var reserve int
+	if banner {
+		// the longest banner is when every line is a part.
+		reserve = len(sectionBanner(len(lines), len(lines), e.name, e.eol))
+	}
+	var parts [][]byte
+	var cur []byte
+	for _, line := range lines {
+		if reserve+len(line) > maxBytes {
+			return nil, fmt.Errorf("uuencode: part size %d too small",
+				maxBytes)
+		} else if reserve+len(cur)+len(line) > maxBytes {
+			parts = append(parts, cur)
+			cur = nil
+		}
+		cur = append(cur, line...)
+	}
+	parts = append(parts, cur)
+	if banner {
+		for i, p := range parts {
+			b := sectionBanner(i+1, len(parts), e.name, e.eol)
+			parts[i] = append([]byte(b), p...)
+		}
+	}
+	return parts, nil
+}
+
+// sectionBanner returns the banner line of part i of n.
+func sectionBanner(i, n int, name, eol string) string {
+	return fmt.Sprintf("section %d of %d of file %s%s", i, n, name, eol)
+}
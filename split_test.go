@@ -0,0 +1,98 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+)
+
+func TestSplitter(t *testing.T) {
+	src := "Hello,\n\nsee attachments.\n" +
+		uuencode.EncodeToString([]byte("first")) +
+		"between\n" +
+		uuencode.EncodeToString([]byte("second")) +
+		uuencode.EncodeToString([]byte("third")) +
+		"bye"
+	s := uuencode.NewSplitter(bytes.NewBufferString(src))
+	var got []string
+	for {
+		seg, err := s.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		switch seg := seg.(type) {
+		case uuencode.TextSegment:
+			got = append(got, "text: "+string(seg.Text))
+		case *uuencode.Part:
+			b, err := ioutil.ReadAll(seg)
+			if err != nil {
+				t.Fatal("err at read all:", err)
+			}
+			got = append(got, seg.Header.Name+": "+string(b))
+		}
+	}
+	want := []string{
+		"text: Hello,\n\nsee attachments.\n",
+		"filename: first",
+		"text: between\n",
+		"filename: second",
+		"filename: third",
+		"text: bye",
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestSplitEncode(t *testing.T) {
+	src := make([]byte, 3000)
+	for i := range src {
+		src[i] = byte(i * 5)
+	}
+	for _, banner := range []bool{false, true} {
+		parts, err := uuencode.SplitEncode(src, 500, banner,
+			uuencode.NewEncodeWith(uuencode.WithFilename("big.bin")))
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		if len(parts) < 2 {
+			t.Fatal("Got parts: ", len(parts))
+		}
+		a := uuencode.NewAssembler(len(parts))
+		var all []byte
+		for i, p := range parts {
+			if len(p) > 500 {
+				t.Error(i, "part too long: ", len(p))
+			}
+			if banner && !bytes.HasPrefix(p, []byte(fmt.Sprintf(
+				"section %d of %d of file big.bin\n", i+1, len(parts)))) {
+				t.Errorf("%d got banner: %q", i, p[:40])
+			}
+			all = append(all, p...)
+			a.Add(i+1, p)
+		}
+		if !banner {
+			got, err := uuencode.DecodeString(string(all))
+			if err != nil || !bytes.Equal(got, src) {
+				t.Error("joined parts decoding got: ", err)
+			}
+		}
+		p, err := a.Part()
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		if got, err := ioutil.ReadAll(p); err != nil || !bytes.Equal(got, src) {
+			t.Error("reassembled parts got: ", err)
+		}
+	}
+	if _, err := uuencode.SplitEncode(src, 40, false, nil); err == nil {
+		t.Error("Expecting error for too small part size")
+	}
+}
@@ -0,0 +1,178 @@
+package uuencode
+
+import (
+	"io"
+
+	"golang.org/x/text/transform"
+)
+
+// streamBufSize is the size of the source and destination buffers used by the
+// ReadFrom and WriteTo fast paths.
+const streamBufSize = 32 * 1024
+
+// NewReader returns io.Reader that decodes the first uuencoded content read
+// from r. Like Uue.NewDecoder, any bytes that do not belong to the uuencoded
+// content are passed through as is. The returned reader implements io.WriterTo
+// so io.Copy writes the decoded bytes straight into the destination.
+func NewReader(r io.Reader) io.Reader {
+	d := NewDecode()
+	return &reader{src: r, t: d, tr: transform.NewReader(r, d)}
+}
+
+// NewWriter returns io.WriteCloser that uuencodes the bytes written into it and
+// writes the result into w. e provides the begin line file name, permission and
+// line format; nil e uses the same setting as Uue.NewEncoder. Close must be
+// called to flush the last line and the end marker. Close does not close w.
+// The returned writer implements io.ReaderFrom so io.Copy encodes whole lines
+// straight from the source buffer.
+func NewWriter(w io.Writer, e *Encode) io.WriteCloser {
+	if e == nil {
+		e = NewEncode(true, "\n")
+	} else {
+		e.Reset()
+	}
+	return &writer{dst: w, t: e, tw: transform.NewWriter(w, e)}
+}
+
+// reader wraps transform.Reader with the io.WriterTo fast path.
+type reader struct {
+	src  io.Reader
+	t    transform.Transformer
+	tr   *transform.Reader
+	used bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	r.used = true
+	return r.tr.Read(p)
+}
+
+// WriteTo decodes the rest of the input into w. If Read has been called, the
+// bytes buffered by it are drained through the regular copy loop.
+func (r *reader) WriteTo(w io.Writer) (int64, error) {
+	if r.used {
+		return io.Copy(w, struct{ io.Reader }{r.tr})
+	}
+	r.used = true
+	n, _, err := transformCopy(w, r.src, r.t, true)
+	return n, err
+}
+
+// writer wraps transform.Writer with the io.ReaderFrom fast path.
+type writer struct {
+	dst  io.Writer
+	t    transform.Transformer
+	tw   *transform.Writer
+	used bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.used = true
+	return w.tw.Write(p)
+}
+
+func (w *writer) Close() error {
+	return w.tw.Close()
+}
+
+// ReadFrom encodes the bytes read from r until EOF. If Write has been called,
+// the regular copy loop is used so that the bytes buffered by Write are kept
+// in order. Otherwise the bytes not forming a whole line are left to the next
+// Write or Close.
+func (w *writer) ReadFrom(r io.Reader) (int64, error) {
+	if w.used {
+		return io.Copy(struct{ io.Writer }{w.tw}, r)
+	}
+	cr := &countReader{r: r}
+	_, rest, err := transformCopy(w.dst, cr, w.t, false)
+	if err != nil {
+		return cr.n, err
+	}
+	w.used = true
+	if len(rest) > 0 {
+		if _, err = w.tw.Write(rest); err != nil {
+			return cr.n, err
+		}
+	}
+	return cr.n, nil
+}
+
+// countReader counts the bytes read from r.
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// transformCopy reads r until EOF, transforms the bytes with t and writes the
+// result into w. It returns the number of bytes written and the source bytes
+// that t has not consumed. atEOF tells whether the end of r is also the end of
+// the input of t.
+func transformCopy(w io.Writer, r io.Reader, t transform.Transformer,
+	atEOF bool) (int64, []byte, error) {
+	src := make([]byte, streamBufSize)
+	dst := make([]byte, streamBufSize)
+	var (
+		written     int64
+		src0, src1  int
+		eof         bool
+		needMoreSrc = true
+	)
+	for {
+		if needMoreSrc && !eof {
+			if src0 > 0 {
+				src1 = copy(src, src[src0:src1])
+				src0 = 0
+			}
+			if src1 == len(src) {
+				return written, nil, transform.ErrShortSrc
+			}
+			n, err := r.Read(src[src1:])
+			src1 += n
+			if err == io.EOF {
+				eof = true
+			} else if err != nil {
+				return written, nil, err
+			}
+		}
+		nDst, nSrc, err := t.Transform(dst, src[src0:src1], eof && atEOF)
+		src0 += nSrc
+		if nDst > 0 {
+			n, werr := w.Write(dst[:nDst])
+			written += int64(n)
+			if werr != nil {
+				return written, nil, werr
+			}
+			if n < nDst {
+				return written, nil, io.ErrShortWrite
+			}
+		}
+		needMoreSrc = false
+		switch err {
+		case nil:
+			if eof && src0 == src1 {
+				return written, nil, nil
+			}
+			needMoreSrc = true
+		case transform.ErrShortDst:
+			if nDst == 0 && nSrc == 0 {
+				dst = make([]byte, 2*len(dst))
+			}
+		case transform.ErrShortSrc:
+			if eof {
+				if atEOF {
+					return written, nil, err
+				}
+				return written, src[src0:src1], nil
+			}
+			needMoreSrc = true
+		default:
+			return written, nil, err
+		}
+	}
+}
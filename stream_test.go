@@ -0,0 +1,152 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+)
+
+func TestNewReader(t *testing.T) {
+	const want = "I love you forever."
+	r := uuencode.NewReader(bytes.NewBufferString(
+		"begin 644 pp.txt\n322!L;W9E('EO=2!F;W)E=F5R+@``\n`\nend\n"))
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(got), want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	want := "begin 777 pp.txt\n322!L;W9E('EO=2!F;W)E=F5R+@``\n`\nend\n"
+	b := new(bytes.Buffer)
+	w := uuencode.NewWriter(b, uuencode.NewEncode(true, "\n", "pp.txt", "777"))
+	if _, err := io.WriteString(w, "I love "); err != nil {
+		t.Fatal("err at first write:", err)
+	}
+	if _, err := io.WriteString(w, "you forever."); err != nil {
+		t.Fatal("err at second write:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("err at close:", err)
+	}
+	if diff := pretty.Compare(b.String(), want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestNewWriterReader(t *testing.T) {
+	src := make([]byte, tEncDecSize)
+	for i := range src {
+		src[i] = byte(i * 3)
+	}
+	b := new(bytes.Buffer)
+	w := uuencode.NewWriter(b, nil)
+	if _, err := w.Write(src); err != nil {
+		t.Fatal("err at write:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("err at close:", err)
+	}
+	got, err := ioutil.ReadAll(uuencode.NewReader(b))
+	if err != nil {
+		t.Fatal("err at decoding read all:", err)
+	}
+	if !bytes.Equal(got, src) {
+		t.Error("decoded bytes differ from source")
+	}
+}
+
+func TestNewWriterReadFrom(t *testing.T) {
+	src := make([]byte, tEncDecSize)
+	for i := range src {
+		src[i] = byte(i * 7)
+	}
+	want := new(bytes.Buffer)
+	w := uuencode.NewWriter(want, nil)
+	if _, err := w.Write(src); err != nil {
+		t.Fatal("err at write:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("err at close:", err)
+	}
+	for _, head := range []int{0, 10} {
+		b := new(bytes.Buffer)
+		w := uuencode.NewWriter(b, nil)
+		if _, ok := w.(io.ReaderFrom); !ok {
+			t.Fatal("writer does not implement io.ReaderFrom")
+		}
+		if _, err := w.Write(src[:head]); err != nil {
+			t.Fatal("err at write:", err)
+		}
+		n, err := io.Copy(w, bytes.NewReader(src[head:]))
+		if err != nil {
+			t.Fatal("err at copy:", err)
+		}
+		if n != int64(len(src)-head) {
+			t.Errorf("head %d: copied %d bytes, want %d", head, n,
+				len(src)-head)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal("err at close:", err)
+		}
+		if diff := pretty.Compare(b.String(), want.String()); diff != "" {
+			t.Errorf("head %d: Diff: %s", head, diff)
+		}
+	}
+}
+
+func TestNewReaderWriteTo(t *testing.T) {
+	src := make([]byte, tEncDecSize)
+	for i := range src {
+		src[i] = byte(i * 5)
+	}
+	enc := new(bytes.Buffer)
+	w := uuencode.NewWriter(enc, nil)
+	if _, err := w.Write(src); err != nil {
+		t.Fatal("err at write:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("err at close:", err)
+	}
+	input := "junk\n" + enc.String() + "trailer\n"
+	want := "junk\n" + string(src) + "trailer\n"
+	for _, head := range []int{0, 3} {
+		r := uuencode.NewReader(bytes.NewBufferString(input))
+		if _, ok := r.(io.WriterTo); !ok {
+			t.Fatal("reader does not implement io.WriterTo")
+		}
+		p := make([]byte, head)
+		if _, err := io.ReadFull(r, p); err != nil {
+			t.Fatal("err at read:", err)
+		}
+		b := bytes.NewBuffer(p)
+		n, err := io.Copy(b, r)
+		if err != nil {
+			t.Fatal("err at copy:", err)
+		}
+		if n != int64(len(want)-head) {
+			t.Errorf("head %d: copied %d bytes, want %d", head, n,
+				len(want)-head)
+		}
+		if b.String() != want {
+			t.Errorf("head %d: decoded bytes differ from source", head)
+		}
+	}
+}
+
+func TestNewReaderWriteToError(t *testing.T) {
+	r := uuencode.NewReader(bytes.NewBufferString(
+		"begin 644 a\n#86)C\n`\n"))
+	_, err := io.Copy(ioutil.Discard, r)
+	if !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Expecting ErrBadUUDec but got:", err)
+	}
+}
@@ -0,0 +1,52 @@
+package uuencode
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ErrNoPartNumber indicates a subject without recognizable part numbering.
+var ErrNoPartNumber = errors.New("uuencode: no part number in subject")
+
+// partNumberRe matches the common part numbering of multi-part posts, eg:
+// "(1/5)", "[2/10]" and "part 03 of 12".
+var partNumberRe = regexp.MustCompile(`(?i)[(\[]\s*(\d+)\s*(?:/|of)\s*` +
+	`(\d+)\s*[)\]]|\bpart\s*(\d+)\s*(?:/|of)\s*(\d+)`)
+
+// ParsePartNumber returns the part index, starts from 1, and the total parts
+// of a multi-part post from its subject, eg: "big.zip (2/5)", "[2/10] big.zip"
+// or "big.zip part 03 of 12". If the subject has several numberings, eg: the
+// file count and the part of the file, the last one is used. ok is false if
+// there is no numbering with 1 <= index <= total.
+func ParsePartNumber(subject string) (index, total int, ok bool) {
+	ms := partNumberRe.FindAllStringSubmatch(subject, -1)
+	for i := len(ms) - 1; i >= 0; i-- {
+		m := ms[i]
+		if m[1] == "" {
+			m = m[2:]
+		}
+		index, ierr := strconv.Atoi(m[1])
+		total, terr := strconv.Atoi(m[2])
+		if ierr == nil && terr == nil && index >= 1 && index <= total {
+			return index, total, true
+		}
+	}
+	return 0, 0, false
+}
+
+// AddSubject is like Add but takes the fragment index from the part numbering
+// of the message subject, see ParsePartNumber. It returns ErrNoPartNumber if
+// subject has no part numbering, and error if its total differs from the one
+// given to NewAssembler.
+func (a *Assembler) AddSubject(subject string, fragment []byte) error {
+	index, total, ok := ParsePartNumber(subject)
+	if !ok {
+		return ErrNoPartNumber
+	} else if total != a.total {
+		return fmt.Errorf("uuencode: subject %q has %d parts, want %d",
+			subject, total, a.total)
+	}
+	return a.Add(index, fragment)
+}
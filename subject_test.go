@@ -0,0 +1,65 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sanylcs/uuencode"
+)
+
+var tstParsePartNumberData = []struct {
+	subject      string
+	index, total int
+	ok           bool
+}{
+	{"big.zip (1/5)", 1, 5, true},
+	{"[2/10] big.zip", 2, 10, true},
+	{"big.zip part 03 of 12", 3, 12, true},
+	{"Big.zip - Part 4/7", 4, 7, true},
+	{"[ 5 of 9 ] big.zip", 5, 9, true},
+	{"[1/3] - \"big.zip\" yEnc (07/50)", 7, 50, true},
+	{"big.zip (3/2)", 0, 0, false},
+	{"big.zip (0/2)", 0, 0, false},
+	{"big.zip 1/2", 0, 0, false},
+	{"big.zip", 0, 0, false},
+}
+
+func TestParsePartNumber(t *testing.T) {
+	for _, d := range tstParsePartNumberData {
+		index, total, ok := uuencode.ParsePartNumber(d.subject)
+		if index != d.index || total != d.total || ok != d.ok {
+			t.Errorf("%q Got: %d %d %v Expecting: %d %d %v", d.subject,
+				index, total, ok, d.index, d.total, d.ok)
+		}
+	}
+}
+
+func TestAssemblerAddSubject(t *testing.T) {
+	posts := map[string]string{
+		"pic.gif (2/2)": "#86)C\n`\nend\n",
+		"pic.gif (1/2)": "begin 644 pic.gif\n",
+	}
+	a := uuencode.NewAssembler(2)
+	for subject, body := range posts {
+		if err := a.AddSubject(subject, []byte(body)); err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+	}
+	p, err := a.Part()
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	got, err := ioutil.ReadAll(p)
+	if err != nil || !bytes.Equal(got, []byte("abc")) {
+		t.Error("Got: ", string(got), err)
+	}
+	if err := a.AddSubject("pic.gif", nil); !errors.Is(err,
+		uuencode.ErrNoPartNumber) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrNoPartNumber)
+	}
+	if err := a.AddSubject("pic.gif (1/3)", nil); err == nil {
+		t.Error("Expecting error for mismatched total")
+	}
+}
@@ -0,0 +1,140 @@
+package uuencode
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrBadTable indicates the translation table is not 64 distinct printable
+// characters.
+var ErrBadTable = errors.New("uuencode: bad translation table")
+
+// XXAlphabet is the xxencode characters set.
+var XXAlphabet, _ = NewAlphabet(
+	"+-0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+
+// uuTableMarker is the line starting the historical translation table section
+// before the begin line.
+const uuTableMarker = "table"
+
+// Alphabet is a custom 64 characters set that replaces the standard uuencode
+// characters, eg: xxencode characters. The first character is the zero value
+// which is also used as padding. Alphabet is safe for concurrent use.
+type Alphabet struct {
+	// enc maps 6 bits value to character.
+	enc [64]byte
+	// toStd maps character to the standard character. Invalid character is
+	// mapped to an invalid standard character.
+	toStd [256]byte
+	// fromStd maps the standard character to character.
+	fromStd [256]byte
+}
+
+// NewAlphabet returns Alphabet of chars which must be 64 distinct printable
+// ASCII characters, or ErrBadTable.
+func NewAlphabet(chars string) (*Alphabet, error) {
+	if len(chars) != 64 {
+		return nil, ErrBadTable
+	}
+	a := &Alphabet{}
+	for i := range a.toStd {
+		a.toStd[i] = uuInvalid
+		a.fromStd[i] = byte(i)
+	}
+	// end of line characters are kept for the line scanning.
+	a.toStd['\r'] = '\r'
+	for i := 0; i < 64; i++ {
+		c := chars[i]
+		if c <= ' ' || c > '~' || a.toStd[c] != uuInvalid {
+			return nil, ErrBadTable
+		}
+		a.enc[i] = c
+		a.toStd[c] = uuEncGraveTable[i]
+		a.fromStd[uuEncTable[i]] = c
+	}
+	a.fromStd[uuPadding] = a.enc[0]
+	return a, nil
+}
+
+// String returns the 64 characters.
+func (a *Alphabet) String() string {
+	return string(a.enc[:])
+}
+
+// toStdLine returns line translated into the standard characters. buf is used
+// to store the result if it is big enough.
+func (a *Alphabet) toStdLine(buf, line []byte) []byte {
+	if cap(buf) < len(line) {
+		buf = make([]byte, len(line))
+	}
+	buf = buf[:len(line)]
+	for i, c := range line {
+		buf[i] = a.toStd[c]
+	}
+	return buf
+}
+
+// fromStdLine translates the standard characters of b in place.
+func (a *Alphabet) fromStdLine(b []byte) {
+	for i, c := range b {
+		b[i] = a.fromStd[c]
+	}
+}
+
+// tableReader reads the table section.
+type tableReader struct {
+	table *Alphabet
+	// inTable is set when reading the table section into buf.
+	inTable bool
+	buf     []byte
+}
+
+// read reads line of the table section, the first line is the table marker
+// line.
+func (t *tableReader) read(line []byte) error {
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte{'\n'}), []byte{'\r'})
+	if !t.inTable {
+		t.inTable = true
+		t.buf = t.buf[:0]
+		return nil
+	}
+	t.buf = append(t.buf, line...)
+	if len(t.buf) < 64 {
+		return nil
+	}
+	t.inTable = false
+	a, err := NewAlphabet(string(t.buf))
+	if err != nil {
+		return &HeaderError{Raw: string(t.buf), Err: err}
+	}
+	t.table = a
+	return nil
+}
+
+// isTableLine reports whether line with or without end of line characters
+// is part of the table section.
+func (t *tableReader) isTableLine(line []byte) bool {
+	return t.inTable || string(bytes.TrimRight(line, "\r\n")) == uuTableMarker
+}
+
+// take returns the read table for the next uuencoded content, nil if there is
+// none. Each table only applies to one content.
+func (t *tableReader) take() *Alphabet {
+	a := t.table
+	t.table = nil
+	return a
+}
+
+// reset discards the table section read.
+func (t *tableReader) reset() {
+	t.table, t.inTable, t.buf = nil, false, t.buf[:0]
+}
+
+// isZero reports whether c is the length character of the zero length line
+// before the end marker line.
+func (u *uuBodyDec) isZero(c byte) bool {
+	if u.alpha != nil {
+		return u.alpha.toStd[c] == uuPadding
+	}
+	return c == uuPadding
+}
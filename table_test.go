@@ -0,0 +1,149 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+// tstXXTable is the xxencode characters set.
+const tstXXTable = "+-0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+func TestEncodeTable(t *testing.T) {
+	want := "table\n+-0123456789ABCDEFGHIJKLMNOPQRST\nUVWXYZabcdefghijklmnopqrstuvwxyz\n" +
+		"begin 644 pp.txt\nHGG-gPrNZ65ZjRG-aPr7ZRaJm9U++\n+\nend\n"
+	e := uuencode.NewEncodeWith(uuencode.WithFilename("pp.txt"),
+		uuencode.WithTable(tstXXTable))
+	got, _, err := transform.String(e, "I love you forever.")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	got2, err := uuencode.DecodeString(got)
+	if err != nil || string(got2) != "I love you forever." {
+		t.Error("Got: ", string(got2), err)
+	}
+}
+
+func TestDecodeTable(t *testing.T) {
+	src := make([]byte, 1000)
+	for i := range src {
+		src[i] = byte(i * 7)
+	}
+	e := uuencode.NewEncodeWith(uuencode.WithTable(tstXXTable),
+		uuencode.WithEOL("\r\n"))
+	encoded, _, err := transform.String(e, string(src))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	// the table only applies to the content right after it.
+	in := "junk\n" + encoded + uuencode.EncodeToString([]byte("plain"))
+	d, _, ch := uuencode.NewMultiDecode()
+	done := make(chan [][]byte)
+	go func() {
+		var got [][]byte
+		for r := range ch {
+			b, _ := ioutil.ReadAll(r)
+			got = append(got, b)
+		}
+		done <- got
+	}()
+	out, err := ioutil.ReadAll(transform.NewReader(strings.NewReader(in), d))
+	d.Close()
+	got := <-done
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if string(out) != "junk\n" {
+		t.Errorf("Got: %q Expecting: junk", out)
+	}
+	if len(got) != 2 || !bytes.Equal(got[0], src) ||
+		string(got[1]) != "plain" {
+		t.Error("decoded contents mismatch")
+	}
+}
+
+func TestBadTable(t *testing.T) {
+	e := uuencode.NewEncodeWith(uuencode.WithTable("abc"))
+	if _, _, err := transform.String(e, "a"); err != uuencode.ErrBadTable {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadTable)
+	}
+	in := "table\n" + strings.Repeat("a", 32) + "\n" + strings.Repeat("b", 32) +
+		"\nbegin 644 a\n`\nend\n"
+	_, err := uuencode.DecodeString(in)
+	var de *uuencode.DecodeError
+	if !errors.Is(err, uuencode.ErrBadTable) || !errors.As(err, &de) ||
+		de.Line != 3 {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadTable)
+	}
+}
+
+func TestPartReaderTable(t *testing.T) {
+	e := uuencode.NewEncodeWith(uuencode.WithTable(tstXXTable))
+	encoded, _, err := transform.String(e, "I love you forever.")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	pr := uuencode.NewPartReader(strings.NewReader(encoded +
+		uuencode.EncodeToString([]byte("plain"))))
+	for _, want := range []string{"I love you forever.", "plain"} {
+		p, err := pr.NextPart()
+		if err != nil {
+			t.Fatal("Expecting non-error but got err:", err)
+		}
+		if got, err := ioutil.ReadAll(p); err != nil || string(got) != want {
+			t.Error("Got: ", string(got), err, " Expecting: ", want)
+		}
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	src := make([]byte, 500)
+	for i := range src {
+		src[i] = byte(i * 3)
+	}
+	e := uuencode.NewEncodeWith(uuencode.WithAlphabet(uuencode.XXAlphabet))
+	encoded, _, err := transform.String(e, string(src))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if strings.HasPrefix(encoded, "table") || !strings.HasPrefix(encoded,
+		"begin 644 filename\nh") || !strings.HasSuffix(encoded, "\n+\nend\n") {
+		t.Errorf("Got: %q", encoded)
+	}
+	for _, d := range []*uuencode.Decode{
+		uuencode.NewDecodeWith(uuencode.WithDecodeAlphabet(uuencode.XXAlphabet)),
+		uuencode.NewDecodeWith(uuencode.WithDecodeAlphabet(
+			uuencode.XXAlphabet)).Clone(),
+	} {
+		got, _, err := transform.String(d, encoded)
+		if err != nil || got != string(src) {
+			t.Error("Got: ", err)
+		}
+	}
+	// body only with alphabet.
+	e = uuencode.NewBodyEncode(uuencode.WithAlphabet(uuencode.XXAlphabet))
+	body, _, err := transform.String(e, string(src))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d := uuencode.NewBodyDecode(uuencode.WithDecodeAlphabet(uuencode.XXAlphabet))
+	if got, _, err := transform.String(d, body); err != nil || got != string(src) {
+		t.Error("Got: ", err)
+	}
+	if uuencode.XXAlphabet.String()[:3] != "+-0" {
+		t.Error("Got: ", uuencode.XXAlphabet.String())
+	}
+	if _, err := uuencode.NewAlphabet(strings.Repeat("a", 64)); err !=
+		uuencode.ErrBadTable {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadTable)
+	}
+}
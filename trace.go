@@ -0,0 +1,93 @@
+package uuencode
+
+import "bytes"
+
+// TraceKind is the kind of TraceEvent.
+type TraceKind int
+
+const (
+	// TraceState is sent when the decoding state changes.
+	TraceState TraceKind = iota
+	// TraceHeader is sent when a begin line is parsed.
+	TraceHeader
+	// TraceLine is sent for every uuencoded body line decoded, including the
+	// grave and end marker lines.
+	TraceLine
+	// TracePartOpen is sent when an uuencoded content starts.
+	TracePartOpen
+	// TracePartClose is sent when the end marker line of an uuencoded content
+	// is decoded.
+	TracePartClose
+)
+
+var traceKindNames = [...]string{"state", "header", "line", "part open",
+	"part close"}
+
+func (k TraceKind) String() string {
+	if k < 0 || int(k) >= len(traceKindNames) {
+		return "unknown"
+	}
+	return traceKindNames[k]
+}
+
+// stateNames are the names of decoding states reported by TraceState.
+var stateNames = [...]string{uuStart: "start", uuBody: "body", uuEnd: "end"}
+
+// TraceEvent is a low-level decoding event for debugging misbehaving input.
+type TraceEvent struct {
+	Kind TraceKind
+	// Line, Offset and Part has the same meaning as in DecodeError, they
+	// locate the input line right after the event.
+	Line   int
+	Offset int64
+	Part   int
+	// State is the new decoding state of TraceState: "start", "body" or
+	// "end".
+	State string
+	// Text is the input line without the end of line characters of
+	// TraceHeader and TraceLine.
+	Text string
+}
+
+// OnTrace sets f to be called for every low-level decoding event. f runs in the
+// goroutine calling Transform. Nil f, the default, disables tracing.
+func (d *Decode) OnTrace(f func(TraceEvent)) {
+	d.onTrace = f
+}
+
+// trace sends the event of kind that happens right after consumed.
+func (d *Decode) trace(consumed []byte, kind TraceKind, text string) {
+	if d.onTrace == nil {
+		return
+	}
+	e := TraceEvent{Kind: kind, Text: text}
+	if kind == TraceState {
+		e.State, e.Text = text, ""
+	}
+	e.Line, e.Offset, e.Part = d.position(consumed)
+	d.onTrace(e)
+}
+
+// setState changes the decoding state to state. consumed is the part of
+// current source bytes that has been processed.
+func (d *Decode) setState(consumed []byte, state int) {
+	d.state = state
+	d.trace(consumed, TraceState, stateNames[state])
+}
+
+// traceLines sends TraceLine for every line of src[start:end].
+func (d *Decode) traceLines(src []byte, start, end int) {
+	if d.onTrace == nil {
+		return
+	}
+	for start < end {
+		n := bytes.IndexByte(src[start:end], '\n')
+		next := start + n + 1
+		if n < 0 {
+			n, next = end-start, end
+		}
+		line := bytes.TrimSuffix(src[start:start+n], []byte{'\r'})
+		d.trace(src[:start], TraceLine, string(line))
+		start = next
+	}
+}
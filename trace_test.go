@@ -0,0 +1,65 @@
+package uuencode_test
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestDecodeTrace(t *testing.T) {
+	in := "x\nbegin 644 a\r\n#86)C\r\n`\r\nend\r\ny\n"
+	var got []uuencode.TraceEvent
+	d := uuencode.NewDecode()
+	d.OnTrace(func(e uuencode.TraceEvent) {
+		got = append(got, e)
+	})
+	out, _, err := transform.String(d, in)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if out != "x\nabcy\n" {
+		t.Errorf("Got: %q", out)
+	}
+	want := []uuencode.TraceEvent{
+		{Kind: uuencode.TraceHeader, Line: 2, Offset: 2,
+			Text: "begin 644 a"},
+		{Kind: uuencode.TraceState, Line: 3, Offset: 15, State: "body"},
+		{Kind: uuencode.TracePartOpen, Line: 3, Offset: 15},
+		{Kind: uuencode.TraceLine, Line: 3, Offset: 15, Text: "#86)C"},
+		{Kind: uuencode.TraceLine, Line: 4, Offset: 22, Text: "`"},
+		{Kind: uuencode.TraceLine, Line: 5, Offset: 25, Text: "end"},
+		{Kind: uuencode.TracePartClose, Line: 6, Offset: 30},
+		{Kind: uuencode.TraceState, Line: 6, Offset: 30, State: "end"},
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestMultiDecodeTrace(t *testing.T) {
+	in := "begin 644 a\n#86)C\n`\nend\nbegin 644 b\n`\nend\n"
+	d, _, ch := uuencode.NewMultiDecodeParts()
+	var kinds []string
+	d.OnTrace(func(e uuencode.TraceEvent) {
+		if e.Kind != uuencode.TraceLine {
+			kinds = append(kinds, e.Kind.String()+" "+e.State)
+		}
+	})
+	go func() {
+		for p := range ch {
+			p.Close()
+		}
+	}()
+	if _, _, err := transform.String(d, in); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	d.Close()
+	want := []string{"header ", "state body", "part open ", "part close ",
+		"state start", "header ", "state body", "part open ", "part close ",
+		"state start"}
+	if diff := pretty.Compare(kinds, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
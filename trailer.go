@@ -0,0 +1,141 @@
+package uuencode
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+
+	"golang.org/x/text/transform"
+)
+
+const (
+	// uuCRC32Marker starts the trailer line of the CRC-32 of the decoded
+	// bytes, eg: "crc32 1a2b3c4d".
+	uuCRC32Marker = "crc32 "
+	// uuSizeMarker starts the trailer line of the number of decoded bytes,
+	// eg: "size 12345".
+	uuSizeMarker = "size "
+	// maxTrailerLine is the maximum length of trailer line.
+	maxTrailerLine = 64
+)
+
+// The kinds of line following the end marker line. The trailer lines are at
+// most a size line and then a crc32 line.
+const (
+	// trailerNone is not a trailer line, the trailer lines are over.
+	trailerNone = iota
+	// trailerSize is the size line matching the decoded content.
+	trailerSize
+	// trailerText is the size line not matching the decoded content. It is
+	// kept as text, eg: "size 99" of the text after the content.
+	trailerText
+	// trailerLast is the crc32 line matching the decoded content.
+	trailerLast
+)
+
+// checkTrailer checks the line at the start of src following the end marker
+// line, or the size line after it, and returns its length and kind. The length
+// is zero for trailerNone. *ChecksumError is returned if it is a crc32 line not
+// matching the decoded content.
+func (d *Decode) checkTrailer(src []byte, atEOF bool) (int, int, error) {
+	n := bytes.IndexByte(src, '\n')
+	line := src
+	if n >= 0 {
+		line = src[:n]
+	} else if !atEOF && len(src) < maxTrailerLine {
+		return 0, trailerNone, transform.ErrShortSrc
+	}
+	kind, err := checkTrailerLine(line, d.crc, d.partSize, d.trailerSize)
+	if kind == trailerNone || err != nil {
+		return 0, kind, err
+	} else if n < 0 {
+		return len(src), kind, nil
+	}
+	return n + 1, kind, nil
+}
+
+// checkTrailerLine returns the kind of line of the uuencoded content whose
+// decoded bytes have CRC-32 crc and length size. sizeSeen reports whether the
+// size line has been checked, so line can only be the crc32 line. Only the
+// well-formed lines are trailer lines, ie: the decimal size and the 8 hex
+// digits crc32. *ChecksumError is returned if the crc32 line does not match.
+func checkTrailerLine(line []byte, crc uint32, size int64, sizeSeen bool) (
+	int, error) {
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte{'\n'}),
+		[]byte{'\r'})
+	switch {
+	case bytes.HasPrefix(line, []byte(uuCRC32Marker)):
+		v := string(line[len(uuCRC32Marker):])
+		want, err := strconv.ParseUint(v, 16, 32)
+		if err != nil || len(v) != 8 {
+			return trailerNone, nil
+		} else if want != uint64(crc) {
+			return trailerNone, &ChecksumError{Kind: "crc32", Want: want,
+				Got: uint64(crc)}
+		}
+		return trailerLast, nil
+	case bytes.HasPrefix(line, []byte(uuSizeMarker)) && !sizeSeen:
+		want, err := strconv.ParseUint(string(line[len(uuSizeMarker):]), 10,
+			64)
+		if err != nil {
+			return trailerNone, nil
+		} else if want != uint64(size) {
+			return trailerText, nil
+		}
+		return trailerSize, nil
+	}
+	return trailerNone, nil
+}
+
+// trailer returns the trailer lines outputted after the end marker line.
+func (e *Encode) trailer() string {
+	var t string
+	if e.bodyOnly {
+		return t
+	}
+	if e.sizeTrailer {
+		t = fmt.Sprintf("%s%d%s", uuSizeMarker, e.size, e.eol)
+	}
+	if e.crcTrailer {
+		t += fmt.Sprintf("%s%08x%s", uuCRC32Marker, e.crc, e.eol)
+	}
+	return t
+}
+
+// trailerLen returns the length of the trailer lines outputted for n source
+// bytes.
+func (e *Encode) trailerLen(n int) int {
+	var l int
+	if e.bodyOnly {
+		return l
+	}
+	if e.sizeTrailer {
+		l += len(uuSizeMarker) + len(strconv.Itoa(n)) + len(e.eol)
+	}
+	if e.crcTrailer {
+		l += len(uuCRC32Marker) + 8 + len(e.eol)
+	}
+	return l
+}
+
+// encodeTrailer counts src for the trailer lines and outputs them into dst
+// once the uuencoded body is finished. The state is uuEnd while the trailer
+// lines are waiting for larger dst.
+func (e *Encode) encodeTrailer(dst, src []byte, finished bool) (int, error) {
+	if e.state == uuBody {
+		e.crc = crc32.Update(e.crc, crc32.IEEETable, src)
+		e.size += int64(len(src))
+		if !finished {
+			return 0, nil
+		}
+		e.state = uuEnd
+	}
+	t := e.trailer()
+	if len(dst) < len(t) {
+		return 0, transform.ErrShortDst
+	}
+	e.state = uuBody
+	e.crc, e.size = 0, 0
+	return copy(dst, t), nil
+}
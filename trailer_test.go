@@ -0,0 +1,236 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+func TestEncodeCRC32Trailer(t *testing.T) {
+	e := uuencode.NewEncodeWith(uuencode.WithFilename("a"),
+		uuencode.WithCRC32Trailer(true), uuencode.WithEOL("\r\n"))
+	want := "begin 644 a\r\n#0V%T\r\n`\r\nend\r\ncrc32 a6130548\r\n"
+	got, _, err := transform.String(e, "Cat")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	if l := e.EncodedLen(3); l != len(want) {
+		t.Error("Got: ", l, " Expecting: ", len(want))
+	}
+	// the trailer line waits for larger dst.
+	e.Reset()
+	dst := make([]byte, len(want))
+	n, m, err := e.Transform(dst[:len(want)-3], []byte("Cat"), true)
+	if err != transform.ErrShortDst || m != 3 {
+		t.Fatal("Got: ", m, err, " Expecting: ", transform.ErrShortDst)
+	}
+	k, _, err := e.Transform(dst[n:], nil, true)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(dst[:n+k]), want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestDecodeCRC32Trailer(t *testing.T) {
+	enc := uuencode.NewEncodeWith(uuencode.WithCRC32Trailer(true))
+	in, _, err := transform.String(enc, "Cat")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	// the trailer line is consumed, the text after it is kept.
+	got, _, err := transform.String(uuencode.NewDecode(), in+"text\n")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(got, "Cattext\n"); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// a bad trailer line fails with its position.
+	bad := strings.Replace(in, "crc32 a6130548", "crc32 a6130549", 1)
+	_, _, err = transform.String(uuencode.NewDecode(), bad)
+	var derr *uuencode.DecodeError
+	if !errors.As(err, &derr) || derr.Line != 5 {
+		t.Fatalf("Expecting *uuencode.DecodeError but got %v", err)
+	}
+	want := &uuencode.ChecksumError{Kind: "crc32", Want: 0xa6130549,
+		Got: 0xa6130548}
+	if diff := pretty.Compare(derr.Err, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	if !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
+	}
+	// the consumer of multiple decoding gets the failure too.
+	d, _, ch := uuencode.NewMultiDecode()
+	errc := make(chan error, 1)
+	go func() {
+		var errs []error
+		for r := range ch {
+			_, err := io.Copy(ioutil.Discard, r)
+			errs = append(errs, err)
+		}
+		if len(errs) != 2 || errs[0] != nil {
+			errc <- errors.New("unexpected reader errors")
+			return
+		}
+		errc <- errs[1]
+	}()
+	_, err = io.Copy(ioutil.Discard, transform.NewReader(
+		bytes.NewBufferString(in+bad), d))
+	d.Close()
+	if !errors.As(err, &derr) || derr.Line != 10 {
+		t.Errorf("Expecting *uuencode.DecodeError but got %v", err)
+	}
+	if err = <-errc; !errors.As(err, &derr) {
+		t.Errorf("Expecting *uuencode.DecodeError but got %v", err)
+	}
+}
+
+func TestSizeTrailer(t *testing.T) {
+	e := uuencode.NewEncodeWith(uuencode.WithFilename("a"),
+		uuencode.WithSizeTrailer(true), uuencode.WithCRC32Trailer(true))
+	in, _, err := transform.String(e, "Cat")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	want := "begin 644 a\n#0V%T\n`\nend\nsize 3\ncrc32 a6130548\n"
+	if diff := pretty.Compare(in, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	if l := e.EncodedLen(3); l != len(want) {
+		t.Error("Got: ", l, " Expecting: ", len(want))
+	}
+	tsts := []struct {
+		in  string
+		out string
+		err error
+	}{
+		{in: in + "text", out: "Cattext"},
+		// the size is of the decoded bytes before text mode conversion.
+		{in: "begin 644 a\n#80IB\n`\nend\r\nsize 3\r\n", out: "a\r\nb"},
+		{in: "begin 644 a\n`\nend\nsize 0\n"},
+		// not a trailer line.
+		{in: "begin 644 a\n#0V%T\n`\nend\nsize of it\n",
+			out: "Catsize of it\n"},
+		// the size line not matching is text, eg: of a mail.
+		{in: "begin 644 a\n#0V%T\n`\nend\nsize 99\n",
+			out: "Catsize 99\n"},
+		{in: "begin 644 a\n#0V%T\n`\nend\nsize 3\nsize 3\n",
+			out: "Catsize 3\n"},
+		{in: "begin 644 a\n#0V%T\n`\nend\ncrc32 a6130548\nsize 3\n",
+			out: "Catsize 3\n"},
+		{in: "begin 644 a\n#0V%T\n`\nend\ntext\nsize 3\n",
+			out: "Cattext\nsize 3\n"},
+		{in: "begin 644 a\n#0V%T\n`\nend\ncrc32 a613054\n",
+			out: "Catcrc32 a613054\n"},
+		// the crc32 line is still verified after it.
+		{
+			in: "begin 644 a\n#0V%T\n`\nend\nsize 4\ncrc32 a6130549\n",
+			err: &uuencode.DecodeError{Line: 6, Offset: 31,
+				Err: &uuencode.ChecksumError{Kind: "crc32",
+					Want: 0xa6130549, Got: 0xa6130548}},
+		},
+	}
+	for i, tst := range tsts {
+		d := uuencode.NewDecode()
+		if i == 1 {
+			d = uuencode.NewDecodeWith(uuencode.WithTextEOL("\r\n"))
+		}
+		out, _, err := transform.String(d, tst.in)
+		if diff := pretty.Compare(err, tst.err); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+		if err == nil && out != tst.out {
+			t.Errorf("%d Got: %q Expecting: %q", i, out, tst.out)
+		}
+	}
+	// the text of multiple decoding keeps the size line not matching.
+	d, _, ch := uuencode.NewMultiDecode()
+	go func() {
+		for r := range ch {
+			io.Copy(ioutil.Discard, r)
+		}
+	}()
+	out, err := ioutil.ReadAll(transform.NewReader(strings.NewReader(
+		"begin 644 a\n#0V%T\n`\nend\nsize 99\ntext\n"), d))
+	d.Close()
+	if err != nil || string(out) != "size 99\ntext\n" {
+		t.Errorf("Got: %q %v", out, err)
+	}
+}
+
+func TestPartReaderTrailer(t *testing.T) {
+	good := "begin 644 a\n#0V%T\n`\nend\nsize 3\ncrc32 a6130548\n"
+	bad := "begin 644 b\n#0V%T\n`\nend\r\ncrc32 a6130549\r\n"
+	pr := uuencode.NewPartReader(strings.NewReader(good + "text\n" + bad))
+	var got []string
+	for {
+		p, err := pr.NextPart()
+		if err != nil {
+			got = append(got, err.Error())
+			break
+		}
+		b, err := ioutil.ReadAll(p)
+		got = append(got, p.Header.Name+" "+string(b))
+		if err != nil {
+			got = append(got, err.Error())
+		}
+	}
+	fail := "uuencode: crc32 mismatch, want 2786264393 got 2786264392 at " +
+		"line 12 (offset 76, part 1)"
+	want := []string{"a Cat", "b Cat", fail, fail}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// every reader of PartReader verifies the trailer lines.
+	dir, err := ioutil.TempDir("", "uuencode")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "b.uu")
+	if err = ioutil.WriteFile(name, []byte(bad), 0644); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	readers := map[string]func() error{
+		"DecodeFile": func() error {
+			f, err := uuencode.DecodeFile(strings.NewReader(bad))
+			if err == nil {
+				_, err = ioutil.ReadAll(f.Body)
+			}
+			return err
+		},
+		"ReadFile": func() error {
+			_, _, err := uuencode.ReadFile(name)
+			return err
+		},
+		"ArchiveReader": func() error {
+			ar := uuencode.NewArchiveReader(strings.NewReader(bad))
+			_, err := ar.Next()
+			if err == nil {
+				_, err = ioutil.ReadAll(ar)
+			}
+			return err
+		},
+	}
+	for name, read := range readers {
+		var cerr *uuencode.ChecksumError
+		if err := read(); !errors.As(err, &cerr) || cerr.Kind != "crc32" {
+			t.Errorf("%s Got: %v", name, err)
+		}
+	}
+}
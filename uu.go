@@ -7,14 +7,16 @@ package uuencode
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
-	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
-	"strconv"
 	"strings"
 	"sync"
 
+	"golang.org/x/net/context"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/transform"
 )
@@ -25,7 +27,8 @@ type uuEncoding struct{}
 var Uue = uuEncoding{}
 
 // NewDecoder implments encoding.Decoder. It only decodes first encountered
-// uuencode begin header line.
+// uuencode begin header line. To keep decoding the later uuencoded contents,
+// use encoding.Decoder of Decode created with WithConcat.
 func (uuEncoding) NewDecoder() *encoding.Decoder {
 	return &encoding.Decoder{
 		Transformer: NewDecode(),
@@ -48,6 +51,12 @@ var (
 	// ErrUuCancel indicates there is a cancelation request triggered
 	// internnally that stop the transforming process.
 	ErrUuCancel = errors.New("uuencode: decoder cancel processing")
+	// ErrTooLarge indicates the decoded contents exceed the size limit set by
+	// WithMaxPartSize or WithMaxTotalSize.
+	ErrTooLarge = errors.New("uuencode: decoded content too large")
+	// ErrTooManyParts indicates the input has more uuencoded contents than
+	// the limit set by WithMaxParts.
+	ErrTooManyParts = errors.New("uuencode: too many uuencoded contents")
 	// errFoundEOF is used internnally to indicate end line marker found for one
 	// section of uuencoded contents.
 	errFoundEOF = errors.New("uuencode: found EOF marker")
@@ -59,6 +68,9 @@ const (
 	uuPadding     = '`'
 	uuBeginMarker = "begin"
 	uuEndMarker   = "end"
+	// utf8BOM is the byte order mark that editors may put at the start of
+	// file.
+	utf8BOM       = "\xef\xbb\xbf"
 	maxSingleLine = 45
 	maxEncLine    = 61
 	// max characters per line is marked as M in uuencoding.
@@ -78,13 +90,86 @@ type Decode struct {
 	multi    bool
 	multiErr error
 	cancel   chan struct{}
+	closed   bool // set by Close
 	internal []byte
 	ch       chan io.ReadCloser
+	partCh   chan MultiPart
 	sync.Mutex
-	pipeR      *io.PipeReader
-	pipeW      *io.PipeWriter
-	warn       int
-	state      int
+	pipeR  pipeReader
+	pipeW  pipeWriter
+	warn   int
+	state  int
+	header Header
+	// headerOff is the byte offset of the begin line of header.
+	headerOff int64
+	// line, offset and parts are the total lines, bytes and begin lines
+	// consumed, used for error reporting.
+	line   int
+	offset int64
+	parts  int
+	// onHeader is called when begin line is parsed.
+	onHeader func(Header)
+	// onTrace is called for every low-level decoding event.
+	onTrace func(TraceEvent)
+	// onWarning is called when recoverable oddity is found. warnings keeps
+	// the found warnings.
+	onWarning func(Warning)
+	warnings  []Warning
+	// lenient skips invalid uuencoded body lines instead of failing.
+	lenient bool
+	// concat decodes all uuencoded contents for single decoding.
+	concat bool
+	// stripBOM drops the leading UTF-8 BOM from the outputted bytes.
+	stripBOM bool
+	// maxPart and maxTotal limit the decoded bytes of each uuencoded content
+	// and all contents. Zero means no limit.
+	maxPart, maxTotal int64
+	// maxParts limits the number of uuencoded contents. Zero means no limit.
+	maxParts int
+	// pipeBuf is the buffer size between decoder and consumer of multiple
+	// uuencoded contents decoding. Zero means no buffering.
+	pipeBuf int
+	// stage is the buffer of decoded bytes for multiple uuencoded contents
+	// decoding.
+	stage []byte
+	// textEOL is the end of line that CRLF, CR and LF of the decoded contents
+	// are converted to. Empty means no conversion. lastCR is set if the last
+	// converted byte is CR.
+	textEOL string
+	lastCR  bool
+	// tab reads the translation table for the next uuencoded content.
+	tab tableReader
+	// baseAlpha is the characters set used without the table section.
+	baseAlpha *Alphabet
+	// ctx cancels the decoding when it is done. done stops the goroutine
+	// watching ctx.
+	ctx  context.Context
+	done chan struct{}
+	// out counts the outputted bytes for progress.
+	out        int64
+	onProgress func(Progress)
+	// partSize and totalSize count the decoded bytes.
+	partSize, totalSize int64
+	// newHash creates the hash of every uuencoded content, nil for no digest.
+	// hash is the one of the current content, digest is the sum of the last
+	// finished content and partSum carries it to the multiple decoding
+	// consumer.
+	newHash func() hash.Hash
+	hash    hash.Hash
+	digest  []byte
+	partSum *partDigest
+	// crc is the CRC-32 of the decoded bytes of the current content for its
+	// trailer line. trailer is set after the end marker line until the lines
+	// after it are checked for trailer, trailerSize is set once the size line
+	// is checked.
+	crc                  uint32
+	trailer, trailerSize bool
+	// Filename and Permission are the unvalidated begin line fields. Header
+	// method provides the parsed begin line.
+	//
+	// Deprecated: Transform overwrites them for every begin line, so reading
+	// them in another goroutine is a data race. Use Header, or PartHeader and
+	// NewMultiDecodeParts that deliver the begin line with each content.
 	Filename   string
 	Permission string
 }
@@ -121,18 +206,88 @@ func NewDecode() *Decode {
 // For multiple uuencoded contents, Transform will block. dst will out any
 // content that isn't belong to uuencoded body. Refer to Get method for decoded
 // uuencoded contents.
+//
+// Decoding failure is returned as *DecodeError that records where the failure
+// happens.
 func (d *Decode) Transform(dst, src []byte, atEOF bool) (int, int, error) {
-	var nDst, nSrc int
-	maxLen := len(src)
-	if maxLen == 0 {
-		if d.state == uuEnd || d.multi && d.state == uuStart {
-			return 0, 0, nil // good ending
+	if d.ctx != nil && d.ctx.Err() != nil {
+		d.closePipe()
+		return 0, 0, d.cancelErr()
+	}
+	nDst, nSrc, err := d.transform(dst, src, atEOF)
+	if atEOF && err == nil && nSrc == len(src) {
+		// all input is consumed, check the decoding ends in good state.
+		err = d.endCheck()
+	}
+	if errors.Is(err, ErrBadUUDec) || errors.Is(err, ErrBadLen) ||
+		errors.Is(err, ErrBadTable) || err == ErrTooLarge || err == ErrTooManyParts {
+		err = d.posError(src[:nSrc], err)
+		if d.multi {
+			// let the reader of current uuencoded content know the failure.
+			d.Lock()
+			if d.pipeW != nil {
+				d.pipeW.CloseWithError(err)
+			}
+			d.Unlock()
 		}
-		return 0, 0, ErrBadUUDec
 	}
+	// keep track the position of input for error reporting.
+	d.line += bytes.Count(src[:nSrc], []byte{'\n'})
+	d.offset += int64(nSrc)
+	d.out += int64(nDst)
+	if d.onProgress != nil && (nDst > 0 || nSrc > 0) {
+		_, _, part := d.position(nil)
+		d.onProgress(Progress{In: d.offset, Out: d.out, Part: part})
+	}
+	return nDst, nSrc, err
+}
+
+// endCheck returns error if the input ends at current decoding state.
+func (d *Decode) endCheck() error {
+	if d.bodyOnly || d.state == uuEnd || d.multi && d.state == uuStart ||
+		d.concat && d.state == uuStart && d.parts > 0 {
+		return nil // good ending
+	} else if d.state == uuBody {
+		return &MissingEndError{}
+	}
+	// single decoding does not find any uuencoded content.
+	return ErrBadUUDec
+}
+
+// transform does the actual work of Transform.
+func (d *Decode) transform(dst, src []byte, atEOF bool) (int, int, error) {
+	var nDst, nSrc int
+	maxLen := len(src)
 	for {
+		if d.trailer {
+			n, kind, err := d.checkTrailer(src[nSrc:], atEOF)
+			if err != nil {
+				return nDst, nSrc, err
+			} else if kind == trailerText {
+				if len(dst[nDst:]) < n {
+					return nDst, nSrc, transform.ErrShortDst
+				}
+				nDst += copy(dst[nDst:], src[nSrc:nSrc+n])
+			}
+			nSrc += n
+			if kind == trailerSize || kind == trailerText {
+				d.trailerSize = true
+				continue
+			}
+			d.trailer, d.trailerSize = false, false
+			if d.multi {
+				d.pipeW.Close()
+			}
+		}
 		switch d.state {
 		case uuStart:
+			if d.bodyOnly {
+				// no begin line, the body starts right away.
+				d.parts++
+				d.setState(src[:nSrc], uuBody)
+				d.trace(src[:nSrc], TracePartOpen, "")
+				continue
+			}
 			// search the begin header line
 			for n := nSrc; n < maxLen; n++ {
 				// find EOL
@@ -141,10 +296,24 @@ func (d *Decode) Transform(dst, src []byte, atEOF bool) (int, int, error) {
 				}
 				// found EOL
 				begin := src[nSrc:n]
+				bom := d.bomLen(begin, nSrc)
+				begin = begin[bom:]
+				if d.tab.isTableLine(begin) {
+					if err := d.tab.read(begin); err != nil {
+						return nDst, nSrc, err
+					}
+					nSrc = n + 1
+					continue
+				}
 				if !bytes.HasPrefix(begin, []byte(uuBeginMarker)) {
+					start := nSrc
+					if d.stripBOM {
+						nSrc += bom
+					}
 					if len(dst[nDst:]) < len(src[nSrc:n+1]) {
 						return nDst, nSrc, transform.ErrShortDst
 					}
+					d.checkCR(src[:start], begin)
 					m := copy(dst[nDst:], src[nSrc:n+1])
 					nDst += m
 					nSrc += m
@@ -154,77 +323,154 @@ func (d *Decode) Transform(dst, src []byte, atEOF bool) (int, int, error) {
 				if begin[lastIndex] == '\r' {
 					begin = begin[:lastIndex]
 				}
+				if d.maxParts > 0 && d.parts >= d.maxParts {
+					return nDst, nSrc, ErrTooManyParts
+				}
 				// get the file permission and filename here
+				h := parseHeader(begin)
+				d.Lock()
+				d.header = h
+				d.Unlock()
+				_, d.headerOff, _ = d.position(src[:nSrc])
+				d.trace(src[:nSrc], TraceHeader, d.header.Raw)
+				d.parts++
+				d.partSize = 0
+				d.crc = 0
+				d.hash = nil
+				d.lastCR = false
+				if d.alpha = d.tab.take(); d.alpha == nil {
+					d.alpha = d.baseAlpha
+				}
+				if d.onHeader != nil {
+					d.onHeader(d.header)
+				}
 				as := strings.Split(string(begin), " ")
 				aslen := len(as)
 				if aslen > 2 {
-					d.Filename = as[2]
+					d.Filename = h.Name
 				}
 				if aslen > 1 {
-					if _, err := strconv.Atoi(as[1]); err == nil {
+					if _, err := parseMode(as[1]); err == nil {
 						d.Permission = as[1]
 					}
 				}
 				nSrc = n + 1
-				d.state = uuBody
+				d.setState(src[:nSrc], uuBody)
+				d.trace(src[:nSrc], TracePartOpen, "")
 				break
 			}
 			if d.state != uuBody {
-				if nSrc != 0 {
+				rest := src[nSrc:]
+				bom := d.bomLen(rest, nSrc)
+				if len(rest) == 0 {
+					return nDst, nSrc, nil
+				} else if atEOF {
+					// the last line without end of line characters.
+					if bytes.HasPrefix(rest[bom:], []byte(uuBeginMarker)) {
+						return nDst, nSrc, &MissingEndError{}
+					} else if d.stripBOM {
+						rest = rest[bom:]
+					}
+					if len(dst[nDst:]) < len(rest) {
+						return nDst, nSrc, transform.ErrShortDst
+					}
+					d.checkCR(src[:nSrc], rest)
+					nDst += copy(dst[nDst:], rest)
+					return nDst, maxLen, nil
+				} else if nSrc != 0 || maxLen < defaultMaxBuff {
+					// src may not be the full buffer eg: transform.Writer
+					// pass any written bytes as is.
 					return nDst, nSrc, transform.ErrShortSrc
 				}
 				// nSrc not move and n == maxlen == maximun available internal
 				// buffer
-				if !strings.HasPrefix(string(src[nSrc:]), "begin") {
+				if !bytes.HasPrefix(rest[bom:], []byte(uuBeginMarker)) {
 					return nDst, nSrc, ErrBadUUDec
 				}
-				return nDst, nSrc, ErrBadLen
+				raw := src
+				if len(raw) > maxUuDecLine {
+					raw = raw[:maxUuDecLine]
+				}
+				return nDst, nSrc, &HeaderError{Raw: string(raw), Err: ErrBadLen}
 			} else if d.multi {
 				// if multi decoding uuencoded contents, then create piped files
 				// which allow this method to pass the decoded contents to
 				// another chan and the process state is controlled through the
 				// chan.
 				d.multiErr = nil
-				r, w := io.Pipe()
+				r, w := d.newPipe()
 				d.Lock()
 				d.pipeR = r
 				d.pipeW = w
 				d.Unlock()
-				select {
-				case d.ch <- r:
-				case <-d.cancel:
+				d.partSum = nil
+				if d.newHash != nil {
+					d.partSum = new(partDigest)
+				}
+				if !d.send(r) {
 					d.closePipe()
-					return nDst, nSrc, ErrUuCancel
+					return nDst, nSrc, d.cancelErr()
 				}
 			}
 			fallthrough
 		case uuBody:
 			// after the begin header line found, here start the real uuencoded
 			// decoding process.
-			mDst, mSrc, err := d.uuBodyDec.Transform(dst[nDst:], src[nSrc:],
+			bodyDst := dst[nDst:]
+			if d.multi {
+				// decoded bytes go to the pipe, so they are staged in the
+				// internal buffer regardless of the dst size.
+				if d.stage == nil {
+					d.stage = make([]byte, defaultMaxBuff)
+				}
+				bodyDst = d.stage
+			}
+			if d.textEOL != "" {
+				// leave room for converting every byte into end of line.
+				bodyDst = bodyDst[:len(bodyDst)/len(d.textEOL)]
+			}
+			mDst, mSrc, err := d.uuBodyDec.Transform(bodyDst, src[nSrc:],
 				atEOF)
+			if lerr := d.limit(mDst); lerr != nil {
+				return nDst, nSrc, lerr
+			}
+			d.crc = crc32.Update(d.crc, crc32.IEEETable, bodyDst[:mDst])
+			if d.textEOL != "" {
+				d.internal = append(d.internal[:0], bodyDst[:mDst]...)
+				if d.multi {
+					mDst = d.convertEOL(d.stage, d.internal)
+				} else {
+					mDst = d.convertEOL(dst[nDst:], d.internal)
+				}
+			}
+			if d.multi {
+				d.sum(d.stage[:mDst])
+			} else {
+				d.sum(dst[nDst : nDst+mDst])
+			}
+			for _, fix := range d.fixes {
+				d.warning(src[:nSrc+fix.pos], fix.reason, fix.text)
+			}
+			d.fixes = d.fixes[:0]
+			d.traceLines(src, nSrc, nSrc+mSrc)
 			nSrc += mSrc
 			if d.multi && d.multiErr == nil {
-				wdst := dst[nDst:]
-				// if err == transform.ErrShortDst && mDst == 0 && mSrc == 0 {
-				// 	// ErrShortDst can not be propagate out without adv
-				// 	// dst and src length.
-				// 	if d.internal == nil {
-				// 		d.internal = make([]byte, defaultMaxBuff)
-				// 	}
-				// 	wdst = d.internal
-				// 	mDst, mSrc, err = d.uuBodyDec.Transform(d.internal,
-				// 		src[nSrc:], atEOF)
-				// 	nSrc += mSrc
-				// }
+				wdst := d.stage
 				if mDst > 0 {
 					select {
 					case <-d.cancel:
 						d.closePipe()
-						return nDst, nSrc, ErrUuCancel
+						return nDst, nSrc, d.cancelErr()
 					default:
 						_, werr := d.pipeW.Write(wdst[:mDst])
+						d.out += int64(mDst)
 						if werr != nil {
+							select {
+							case <-d.cancel:
+								// write fails because of the cancelation.
+								return nDst, nSrc, d.cancelErr()
+							default:
+							}
 							if werr == ErrUuCancel {
 								return nDst, nSrc, werr
 							}
@@ -235,14 +481,44 @@ func (d *Decode) Transform(dst, src []byte, atEOF bool) (int, int, error) {
 			} else {
 				nDst += mDst
 			}
-			if err != errFoundEOF {
+			if d.lenient {
+				var lerr *BadLineError
+				if errors.As(err, &lerr) {
+					// skip the junk line and continue with the next line.
+					d.warning(src[:nSrc], lerr.Reason, lerr.Line)
+					if n := bytes.IndexByte(src[nSrc:], '\n'); n < 0 {
+						nSrc = len(src) // the last line without end of line
+					} else {
+						nSrc += n + 1
+					}
+					continue
+				}
+			}
+			if d.multi && err == transform.ErrShortDst && mSrc > 0 {
+				// the staging buffer is full and has been written out.
+				continue
+			} else if err != errFoundEOF {
 				return nDst, nSrc, err
-			} else if d.multi {
-				d.state = uuStart
-				d.pipeW.Close()
+			}
+			d.trace(src[:nSrc], TracePartClose, "")
+			d.finishSum()
+			// the pipe is closed after the trailer lines are checked.
+			d.trailer = !d.bodyOnly
+			if d.multi {
+				d.setState(src[:nSrc], uuStart)
+				if !d.trailer {
+					d.pipeW.Close()
+				}
+				continue
+			} else if d.concat {
+				// look for next uuencoded content.
+				d.setState(src[:nSrc], uuStart)
+				continue
+			}
+			d.setState(src[:nSrc], uuEnd)
+			if d.trailer {
 				continue
 			}
-			d.state = uuEnd
 			fallthrough
 		default:
 			// only single uuencoded decode process will fall through here. Any
@@ -257,6 +533,50 @@ func (d *Decode) Transform(dst, src []byte, atEOF bool) (int, int, error) {
 	}
 }
 
+// bomLen returns the length of the UTF-8 BOM that line starts with, if line is
+// the first line of the input. start is the position of line in the current
+// source bytes.
+func (d *Decode) bomLen(line []byte, start int) int {
+	if d.offset+int64(start) == 0 && bytes.HasPrefix(line, []byte(utf8BOM)) {
+		return len(utf8BOM)
+	}
+	return 0
+}
+
+// limit counts n decoded bytes and returns ErrTooLarge if the size limit is
+// exceeded.
+func (d *Decode) limit(n int) error {
+	d.partSize += int64(n)
+	d.totalSize += int64(n)
+	if d.maxPart > 0 && d.partSize > d.maxPart ||
+		d.maxTotal > 0 && d.totalSize > d.maxTotal {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// convertEOL copies src into dst converting CRLF, CR and LF into textEOL, and
+// returns the number of bytes written. dst must have len(textEOL) times of src
+// length. CRLF split across calls is converted once.
+func (d *Decode) convertEOL(dst, src []byte) int {
+	var n int
+	for _, c := range src {
+		switch {
+		case c == '\n' && d.lastCR:
+			// LF of CRLF, the end of line has been written for CR.
+			d.lastCR = false
+		case c == '\r' || c == '\n':
+			n += copy(dst[n:], d.textEOL)
+			d.lastCR = c == '\r'
+		default:
+			dst[n] = c
+			n++
+			d.lastCR = false
+		}
+	}
+	return n
+}
+
 // closePipe close the piped file that transferring the decoded bytes to another
 // goroutine to be expected to be read out. Piped file internally use mutex to
 // handle the synchronization, so it is safe to call the provided Close method
@@ -275,22 +595,180 @@ func (d *Decode) closePipe() {
 // Reset implements golang/x/text/transform.Transformer interface. It reset the
 // transform internal state. Only useful for single decoding process. For
 // multiple uuencoded contents deocding, it does nothing on reseting the reading
-// chan of decoded contents.
+// chan of decoded contents, use ResetMulti instead.
 func (d *Decode) Reset() {
 	d.state = uuStart
+	d.line = 0
+	d.offset = 0
+	d.parts = 0
+	d.partSize = 0
+	d.totalSize = 0
+	d.crc = 0
+	d.trailer, d.trailerSize = false, false
+	d.hash = nil
+	d.partSum = nil
+	d.lastCR = false
+	d.alpha = d.baseAlpha
+	d.tab.reset()
+	d.out = 0
+	d.Lock()
+	d.header = Header{}
+	d.warnings = nil
+	d.digest = nil
+	d.Unlock()
 	d.Permission = ""
 	d.Filename = ""
 }
 
-// Close closes the returned io.ReadCloser chan from NewMultiDecode.
-func (d *Decode) Close() {
-	if d.multi {
+// Close closes the returned io.ReadCloser chan from NewMultiDecode and releases
+// the pipe of the unfinished uuencoded content, whose reader gets ErrUuCancel
+// after the decoded bytes. Close may be called more than once, eg: with defer,
+// the later calls do nothing. It always returns nil, the decoding failure is
+// returned by Transform.
+func (d *Decode) Close() error {
+	d.Lock()
+	closed := d.closed
+	d.closed = true
+	if d.pipeW != nil {
+		d.pipeW.CloseWithError(ErrUuCancel)
+	}
+	d.Unlock()
+	if closed {
+		return nil
+	}
+	if d.partCh != nil {
+		close(d.partCh)
+	} else if d.multi {
 		close(d.ch)
 	}
+	if d.done != nil {
+		close(d.done)
+	}
+	return nil
+}
+
+// ResetMulti resets Decode of NewMultiDecode or NewMultiDecodeWith like Reset
+// and returns the new cancel function and io.ReadCloser chan like
+// NewMultiDecode, so a pooled Decode can decode the next input after Close
+// with its options and buffers. The cancel function and chan returned before
+// must not be used anymore. Decode of NewMultiDecodeContext can not be reused.
+func (d *Decode) ResetMulti() (func(), <-chan io.ReadCloser) {
+	cancel := d.resetMulti()
+	d.partCh = nil
+	d.ch = make(chan io.ReadCloser)
+	return cancel, d.ch
+}
+
+// resetMulti resets d and its multiple uuencoded contents decoding state, and
+// returns the new cancel function.
+func (d *Decode) resetMulti() func() {
+	d.Reset()
+	// release the consumer of the unfinished content if any.
+	d.closePipe()
+	d.Lock()
+	d.pipeR, d.pipeW = nil, nil
+	d.closed = false
+	d.Unlock()
+	d.multiErr = nil
+	csign := make(chan struct{})
+	d.cancel = csign
+	return func() {
+		close(csign)
+		d.closePipe()
+	}
 }
 
 type uuBodyDec struct {
 	transform.NopResetter
+	// repad re-pads lines that trailing padding characters were stripped and
+	// trims trailing white spaces added after the padding.
+	repad bool
+	// padBuf holds re-padded line. The longest line has 63 bytes length
+	// character which need 85 characters.
+	padBuf [88]byte
+	// rejoin joins body lines that were wrapped by transports with their
+	// continuation lines into joinBuf.
+	rejoin  bool
+	joinBuf [88]byte
+	// fixes records the lines repaired during Transform.
+	fixes []lineFix
+	// bodyOnly decodes bare body lines that end at blank line, grave line
+	// or EOF.
+	bodyOnly bool
+	// alpha is the custom characters set, nil for the standard one. Lines are
+	// translated into stdBuf before decoding.
+	alpha  *Alphabet
+	stdBuf []byte
+}
+
+// lineFix records a repaired line. pos is the offset of the line in src.
+type lineFix struct {
+	pos    int
+	reason string
+	text   string
+}
+
+// repadLine returns b adjusted to the length expected by its length character
+// and the reason of adjustment. Reason is empty if b is not adjusted. b must not
+// be empty and must not contain end of line characters.
+func (u *uuBodyDec) repadLine(b []byte) ([]byte, string) {
+	if b[0] < uuOffset || b[0] >= uuPadding {
+		return b, ""
+	}
+	want := (int(b[0]-uuOffset)+2)/3*4 + 1
+	if len(b) < want {
+		n := copy(u.padBuf[:], b)
+		for ; n < want; n++ {
+			u.padBuf[n] = uuPadding
+		}
+		return u.padBuf[:want], "re-padded line"
+	} else if len(b) > want &&
+		len(bytes.TrimRight(b[want:], " \t")) == 0 {
+		return b[:want], "trimmed trailing white spaces"
+	}
+	return b, ""
+}
+
+// rejoinLine returns b joined with its continuation line at the start of rest
+// and the length of the continuation line including end of line characters.
+// The continuation line completes b base on the length character of b and has
+// no matching length character of its own. It returns b and zero if b is not
+// wrapped, or transform.ErrShortSrc if rest does not hold the whole next line.
+// b must not be empty and must not contain end of line characters.
+func (u *uuBodyDec) rejoinLine(b, rest []byte, atEOF bool) ([]byte, int,
+	error) {
+	want := (int(b[0]-uuOffset)+2)/3*4 + 1
+	if len(b) >= want {
+		return b, 0, nil
+	}
+	m := bytes.IndexByte(rest, '\n')
+	adv := m + 1
+	if m < 0 {
+		if !atEOF && len(rest) <= maxUuDecLine {
+			return b, 0, transform.ErrShortSrc
+		} else if !atEOF {
+			return b, 0, nil
+		}
+		m, adv = len(rest), len(rest)
+	}
+	next := bytes.TrimSuffix(rest[:m], []byte{'\r'})
+	if len(next) == 0 || len(b)+len(next) != want {
+		return b, 0, nil
+	}
+	n := copy(u.joinBuf[:], b)
+	copy(u.joinBuf[n:], next)
+	if u.alpha != nil {
+		u.alpha.toStdLine(u.joinBuf[n:want], u.joinBuf[n:want])
+	}
+	if c := u.joinBuf[n]; c == uuPadding && want-n == 1 {
+		// the zero length line before the end marker line.
+		return b, 0, nil
+	} else if uuDecTable[c] != uuInvalid && c != uuPadding &&
+		(int(c-uuOffset)+2)/3*4+1 == want-n {
+		// a body line of its own.
+		return b, 0, nil
+	}
+	return u.joinBuf[:want], adv, nil
 }
 
 const maxUuDecLine = 64
@@ -299,107 +777,209 @@ const maxUuDecLine = 64
 // discover uuencode end marker. It do not maintenance any state. So, any call
 // after errFoundEOF will continue deocoding and most likely output error if the
 // next line is not a valid uuencode formatted line.
-func (uuBodyDec) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+func (u *uuBodyDec) Transform(dst, src []byte, atEOF bool) (int, int, error) {
 	var nDst, nSrc, linelen int
 	srclen := len(src)
 	for nSrc < srclen {
-		m := strings.Index(string(src[nSrc:]), "\n")
+		m := bytes.IndexByte(src[nSrc:], '\n')
+		adv := m + 1 // +1 to include the \n char
 		if m < 0 {
-			if len(src[nSrc:]) > maxUuDecLine {
+			if atEOF && u.bodyOnly {
+				// the last line without end of line characters.
+				m, adv = srclen-nSrc, srclen-nSrc
+			} else if atEOF {
+				// input ends before the end marker line.
+				return nDst, nSrc, &MissingEndError{}
+			} else if len(src[nSrc:]) > maxUuDecLine {
 				return nDst, nSrc, ErrBadLen
+			} else {
+				return nDst, nSrc, transform.ErrShortSrc
 			}
-			return nDst, nSrc, transform.ErrShortSrc
 		}
 		b := src[nSrc : nSrc+m]
-		if b[0] == uuPadding {
+		if u.alpha != nil {
+			u.stdBuf = u.alpha.toStdLine(u.stdBuf, b)
+			b = u.stdBuf
+		}
+		if u.bodyOnly && (len(b) == 0 || b[0] == uuPadding ||
+			string(b) == "\r") {
+			// blank or grave line ends the body without end marker line.
+			return nDst, nSrc + adv, errFoundEOF
+		} else if len(b) == 0 {
+			return nDst, nSrc, badLine(b, ErrBadUUDec, "empty line")
+		} else if b[0] == uuPadding {
 			// uuPadding grave mean 0 total bytes, checking ending procedure
 			endlen := nSrc + m + 1
-			m = strings.Index(string(src[endlen:]), "\n")
+			m = bytes.IndexByte(src[endlen:], '\n')
 			if m < 0 {
-				if atEOF && string(src[endlen:]) == uuEndMarker {
-					// take care of uuencode that end without LF
-					return nDst, endlen + len(src[endlen:]), errFoundEOF
+				if !atEOF {
+					return nDst, nSrc, transform.ErrShortSrc
 				}
-				return nDst, nSrc, transform.ErrShortSrc
-			}
-			b = src[endlen : endlen+m]
-			linelen = len(b)
-			if b[linelen-1] == '\r' {
-				b = b[:linelen-1]
+				// take care of uuencode that end without LF
+				b = bytes.TrimSuffix(src[endlen:], []byte{'\r'})
+				if string(b) == uuEndMarker {
+					return nDst, srclen, errFoundEOF
+				}
+				return nDst, endlen, &MissingEndError{Line: string(b)}
 			}
-			nSrc = endlen + m + 1
+			b = bytes.TrimSuffix(src[endlen:endlen+m], []byte{'\r'})
 			if string(b) == uuEndMarker {
-				return nDst, nSrc, errFoundEOF
+				return nDst, endlen + m + 1, errFoundEOF
 			}
 			// can not has grave (end) marker but without the "end\n" word
-			return nDst, nSrc, ErrBadUUDec
-		} else if b[0] < uuOffset || b[0] > uuPadding {
-			return nDst, nSrc, ErrBadUUDec
+			return nDst, endlen, &MissingEndError{Line: string(b)}
+		} else if uuDecTable[b[0]] == uuInvalid {
+			return nDst, nSrc, badLine(b, ErrBadCount,
+				"invalid length character")
 		}
-		linelen = len(b)
-		if b[linelen-1] == '\r' {
-			b = b[:linelen-1]
-			linelen--
+		if b[len(b)-1] == '\r' {
+			b = b[:len(b)-1]
+		}
+		orig, fix := b, ""
+		if u.rejoin && len(b) > 0 {
+			var (
+				err  error
+				jadv int
+			)
+			b, jadv, err = u.rejoinLine(b, src[nSrc+adv:], atEOF)
+			if err != nil {
+				return nDst, nSrc, err
+			} else if jadv > 0 {
+				adv += jadv
+				fix = "rejoined wrapped line"
+			}
 		}
+		if u.repad && len(b) > 0 {
+			var rfix string
+			if b, rfix = u.repadLine(b); rfix != "" {
+				fix = rfix
+			}
+		}
+		linelen = len(b)
 		linelen-- // first byte is total bytes count which should be removed
 		if linelen%4 != 0 {
-			return nDst, nSrc, ErrBadUUDec
+			return nDst, nSrc, badLine(b, ErrBadUUDec,
+				"length is not multiple of 4")
 		}
 		tmp := linelen / 4 * 3 // total expected decoded chars (include padding)
-		if tmp > len(dst) {
+		if tmp > len(dst[nDst:]) {
 			return nDst, nSrc, transform.ErrShortDst
 		} else if realTotal := int(b[0] - uuOffset); tmp < realTotal {
 			// not enough uuencoded characters to generate origin characters
-			return nDst, nSrc, ErrBadUUDec
+			return nDst, nSrc, badLine(b, ErrShortBody,
+				"shorter than length character")
 		} else {
 			tmp -= realTotal // get the total zero bit bytes (padding bytes)
 			if tmp > 2 {
 				// padding can only either 0, 1 or 2
-				return nDst, nSrc, ErrBadUUDec
+				return nDst, nSrc, badLine(b, ErrBadUUDec,
+					"longer than length character")
 			}
 		}
-		nSrc += m + 1 // total bytes read, +1 to include the \n char
-		b = b[1:]     // remove the first byte from data bytes
-		nDst += miniConvert(dst[nDst:], b)
-		nDst -= tmp // tmp hold the total padding bytes
+		n, ok := miniConvert(dst[nDst:], b[1:]) // skip the length character
+		if !ok {
+			return nDst, nSrc, badLine(b, ErrBadChar, "invalid character")
+		}
+		if fix != "" {
+			u.fixes = append(u.fixes, lineFix{pos: nSrc, reason: fix,
+				text: string(orig)})
+		}
+		nSrc += adv     // total bytes read
+		nDst += n - tmp // tmp hold the total padding bytes
 	}
 	return nDst, nSrc, nil
 }
 
+// uuDecTable maps uuencoded characters to their 6 bits value. Invalid
+// characters map to uuInvalid.
+var uuDecTable = func() (t [256]byte) {
+	for i := range t {
+		t[i] = uuInvalid
+	}
+	for c := byte(uuOffset); c <= uuPadding; c++ {
+		// grave is the same as space which is 0.
+		t[c] = (c - uuOffset) & 0x3f
+	}
+	return
+}()
+
+// uuInvalid marks invalid characters in uuDecTable. Valid values never have the
+// top 2 bits set.
+const uuInvalid = 0xff
+
 // miniConvert converts each minimum quanta bytes of uuencoded contents into
 // actual content. Uuencoding has the same base64 decoded length that is 4 to 3.
-func miniConvert(out []byte, in []byte) int {
+// It returns false if in has invalid uuencoded character.
+func miniConvert(out []byte, in []byte) (int, bool) {
 	var totalConvert int
-	for i := 0; i < len(in); i += 4 {
-		tmp1 := getOffset(in[i+1])
-		out[totalConvert] = (getOffset(in[i+0]) << 2) | ((0x30 & tmp1) >> 4)
-		tmp2 := getOffset(in[i+2])
-		out[totalConvert+1] = (tmp1 << 4) | ((0x3c & tmp2) >> 2)
-		tmp1 = getOffset(in[i+3])
-		out[totalConvert+2] = (tmp2 << 6) | (0x3f & tmp1)
+	for i := 0; i+3 < len(in); i += 4 {
+		c0, c1 := uuDecTable[in[i]], uuDecTable[in[i+1]]
+		c2, c3 := uuDecTable[in[i+2]], uuDecTable[in[i+3]]
+		if (c0|c1|c2|c3)&0xc0 != 0 {
+			return totalConvert, false
+		}
+		out[totalConvert] = c0<<2 | c1>>4
+		out[totalConvert+1] = c1<<4 | c2>>2
+		out[totalConvert+2] = c2<<6 | c3
 		totalConvert += 3
 	}
-	return totalConvert
+	return totalConvert, true
 }
 
-// getOffset get the number of bytes of the line. This information carries on
-// first character of the line.
-func getOffset(c byte) byte {
-	if c != uuPadding {
-		return c - uuOffset
-	}
-	return 0
+// EncodeToString returns the uuencoded string of src. The begin header line is
+// always "begin 644 filename" and grave is used as padding; use NewEncode for
+// other file name, permission or end of line.
+func EncodeToString(src []byte) string {
+	// Encode only ever reports transform.ErrShortDst or transform.ErrShortSrc,
+	// both are resolved by transform.String for a complete in-memory source.
+	s, _, _ := transform.String(NewEncode(true, "\n"), string(src))
+	return s
 }
 
-// HasUuencode quick inefficient hack to check if r contains uuencode contents.
-// It go through the whole transformation, so might as well do the transform.
-func HasUuencode(r io.Reader) bool {
-	r = transform.NewReader(r, Uue.NewDecoder())
-	_, err := ioutil.ReadAll(r)
-	if err == nil {
-		return true
+// DecodeString returns the bytes of the first uuencoded content in s. Any
+// bytes outside the uuencoded content are dropped and decoding stops once the
+// first uuencoded content is read, so content after it is not checked.
+// ErrBadUUDec is returned if s does not contain any uuencoded content.
+func DecodeString(s string) ([]byte, error) {
+	d, cancel, ch := NewMultiDecode()
+	// cancel may be called by either goroutine below but can only run once.
+	var once sync.Once
+	stop := func() { once.Do(cancel) }
+	type decoded struct {
+		b     []byte
+		err   error
+		found bool
+	}
+	result := make(chan decoded, 1)
+	go func() {
+		var res decoded
+		for r := range ch {
+			if !res.found {
+				res.b, res.err = ioutil.ReadAll(r)
+				res.found = true
+				if res.err == nil {
+					// the rest of s is not needed.
+					stop()
+				}
+			}
+			r.Close()
+		}
+		result <- res
+	}()
+	_, err := io.Copy(ioutil.Discard,
+		transform.NewReader(strings.NewReader(s), d))
+	if err != nil {
+		// unblock the reading goroutine that may wait on unfinished content.
+		stop()
 	}
-	return false
+	d.Close()
+	res := <-result
+	if res.found && res.err == nil {
+		return res.b, nil
+	} else if err == nil {
+		err = ErrBadUUDec
+	}
+	return nil, err
 }
 
 // NewEncode return *Encode that can convert bytes into uuencode format.
@@ -437,34 +1017,96 @@ type Encode struct {
 	uuBodyEnc
 	state        int
 	permit, name string
+	// tableErr is the error of invalid table set by WithTable. emitTable
+	// outputs the table section of alpha.
+	tableErr  error
+	emitTable bool
+	// in and out count the bytes consumed and outputted for progress.
+	in, out    int64
+	onProgress func(Progress)
+	// crcTrailer and sizeTrailer output the trailer lines of crc and size
+	// after the end marker line.
+	crcTrailer, sizeTrailer bool
+	crc                     uint32
+	size                    int64
 }
 
 // Transform implements transform.Transformer.
 func (e *Encode) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	nDst, nSrc, err := e.transform(dst, src, atEOF)
+	if nDst > 0 || nSrc > 0 {
+		e.in += int64(nSrc)
+		e.out += int64(nDst)
+		if e.onProgress != nil {
+			e.onProgress(Progress{In: e.in, Out: e.out})
+		}
+	}
+	return nDst, nSrc, err
+}
+
+// transform does the actual work of Transform.
+func (e *Encode) transform(dst, src []byte, atEOF bool) (int, int, error) {
 	var nDst int
-	switch e.state {
-	case uuStart:
+	if e.state == uuStart && !e.bodyOnly {
+		if e.tableErr != nil {
+			return 0, 0, e.tableErr
+		}
 		// encoding start with creating the begin line of uuencoded which
 		// consist of `begin <file permission mode> filename`
-		startline := fmt.Sprint(uuBeginMarker, " ", e.permit, " ", e.name,
-			e.eol)
-		if len(startline) > len(dst) {
+		// copy the parts directly to avoid allocation.
+		name := quoteName(e.name)
+		n := len(uuBeginMarker) + len(e.permit) + len(name) + len(e.eol) + 2
+		if e.emitTable {
+			// the table section is the table line and 2 lines of 32
+			// characters.
+			n += len(uuTableMarker) + 64 + 3*len(e.eol)
+		}
+		if n > len(dst) {
 			return 0, 0, transform.ErrShortDst
 		}
-		nDst = copy(dst, []byte(startline))
-		e.state = uuBody
-		fallthrough
-	default:
-		// this is the main uuencode encoding process
-		m, n, err := e.uuBodyEnc.Transform(dst[nDst:], src, atEOF)
-		return nDst + m, n, err
+		if e.emitTable {
+			nDst = copy(dst, uuTableMarker)
+			nDst += copy(dst[nDst:], e.eol)
+			nDst += copy(dst[nDst:], e.alpha.enc[:32])
+			nDst += copy(dst[nDst:], e.eol)
+			nDst += copy(dst[nDst:], e.alpha.enc[32:])
+			nDst += copy(dst[nDst:], e.eol)
+		}
+		nDst += copy(dst[nDst:], uuBeginMarker+" ")
+		nDst += copy(dst[nDst:], e.permit)
+		dst[nDst] = ' '
+		nDst++
+		nDst += copy(dst[nDst:], name)
+		nDst += copy(dst[nDst:], e.eol)
+	}
+	if e.state == uuEnd {
+		// the trailer lines did not fit into dst.
+		m, err := e.encodeTrailer(dst, nil, true)
+		return m, 0, err
+	}
+	e.state = uuBody
+	// this is the main uuencode encoding process
+	m, n, err := e.uuBodyEnc.Transform(dst[nDst:], src, atEOF)
+	nDst += m
+	if err != nil && err != transform.ErrShortDst &&
+		err != transform.ErrShortSrc {
+		return nDst, n, err
 	}
+	m, terr := e.encodeTrailer(dst[nDst:], src[:n],
+		err == nil && atEOF && n == len(src))
+	if terr != nil {
+		return nDst, n, terr
+	}
+	return nDst + m, n, err
 }
 
 // Reset implements transform.Transformer to reset internal state of Encode eg:
 // begin marker will be output again for the next transformation start.
 func (e *Encode) Reset() {
 	e.state = uuStart
+	e.crc, e.size = 0, 0
+	e.in = 0
+	e.out = 0
 }
 
 // ResetAll call Reset and also reset the file name and permission bit at begin
@@ -478,6 +1120,9 @@ func (e *Encode) ResetAll(permit, name string) {
 type uuBodyEnc struct {
 	useGrave bool   // indicate using ` as zero bits instead of space
 	eol      string // end of line string eg \n or \r\n
+	bodyOnly bool   // omit the grave and end marker lines
+	// alpha is the custom characters set, nil for the standard one.
+	alpha *Alphabet
 	transform.NopResetter
 }
 
@@ -495,35 +1140,105 @@ func (u uuBodyEnc) Transform(dst, src []byte, atEOF bool) (int, int, error) {
 		}
 		dst[nDst] = maxMarker
 		// encode the content into lines of uuencoded lines.
-		lineEncode(dst[nDst+1:], src[nSrc:], maxSingleLine, u.useGrave)
+		fullLineEncode(dst[nDst+1:], src[nSrc:], u.useGrave)
+		if u.alpha != nil {
+			u.alpha.fromStdLine(dst[nDst : nDst+maxEncLine])
+		}
 		nSrc += maxSingleLine
 		nDst += maxEncLine
-		nDst += copy(dst[nDst:], []byte(u.eol))
+		nDst += copy(dst[nDst:], u.eol)
 	}
 	if atEOF {
-		// create the end line marker that base on uuencode spec.
-		endline := fmt.Sprint(u.eol, "`", u.eol, uuEndMarker, u.eol)
-		eollen = len(endline)
+		// the end line marker that base on uuencode spec is eol, grave, eol,
+		// end marker and eol.
 		srclen = len(src[nSrc:])
+		eollen = 1 + len(uuEndMarker) + 2*len(u.eol)
+		if u.bodyOnly {
+			eollen = 0
+		}
+		if srclen > 0 {
+			eollen += len(u.eol)
+		}
 		expectedLen := srclen / 3
 		if srclen%3 > 0 {
 			expectedLen++
 		}
-		expectedLen = expectedLen*4 + 1
+		if srclen > 0 {
+			expectedLen = expectedLen*4 + 1
+		}
 		if len(dst[nDst:]) < expectedLen+eollen {
 			return nDst, nSrc, transform.ErrShortDst
 		}
-		dst[nDst] = byte(srclen) + uuOffset
-		lineEncode(dst[nDst+1:], src[nSrc:], srclen, u.useGrave)
+		if srclen > 0 {
+			dst[nDst] = byte(srclen) + uuOffset
+			lineEncode(dst[nDst+1:], src[nSrc:], srclen, u.useGrave)
+			if u.alpha != nil {
+				u.alpha.fromStdLine(dst[nDst : nDst+expectedLen])
+			}
+		}
 		nSrc += srclen
 		nDst += expectedLen
-		nDst += copy(dst[nDst:], []byte(endline))
+		if srclen > 0 {
+			// end the last data line.
+			nDst += copy(dst[nDst:], u.eol)
+		}
+		if u.bodyOnly {
+			return nDst, nSrc, nil
+		}
+		dst[nDst] = uuPadding
+		if u.alpha != nil {
+			dst[nDst] = u.alpha.enc[0]
+		}
+		nDst++
+		nDst += copy(dst[nDst:], u.eol)
+		nDst += copy(dst[nDst:], uuEndMarker)
+		nDst += copy(dst[nDst:], u.eol)
 	} else {
 		return nDst, nSrc, transform.ErrShortSrc
 	}
 	return nDst, nSrc, nil
 }
 
+// uuEncTable and uuEncGraveTable map 6 bits value into uuencoded character.
+var uuEncTable, uuEncGraveTable = func() (t, g [64]byte) {
+	for i := range t {
+		t[i] = byte(i) + uuOffset
+		g[i] = t[i]
+	}
+	g[0] = uuPadding
+	return
+}()
+
+// fullLineEncode encodes 45 bytes data into 60 bytes uuencoded data. It is the
+// fast path of lineEncode that encodes 6 bytes per 64-bit load.
+func fullLineEncode(dst []byte, src []byte, useGrave bool) {
+	tab := &uuEncTable
+	if useGrave {
+		tab = &uuEncGraveTable
+	}
+	_ = dst[maxEncLine-2] // bounds check hint
+	_ = src[maxSingleLine-1]
+	var j int
+	for i := 0; i+8 <= maxSingleLine; i += 6 {
+		v := binary.BigEndian.Uint64(src[i:])
+		dst[j] = tab[v>>58&0x3f]
+		dst[j+1] = tab[v>>52&0x3f]
+		dst[j+2] = tab[v>>46&0x3f]
+		dst[j+3] = tab[v>>40&0x3f]
+		dst[j+4] = tab[v>>34&0x3f]
+		dst[j+5] = tab[v>>28&0x3f]
+		dst[j+6] = tab[v>>22&0x3f]
+		dst[j+7] = tab[v>>16&0x3f]
+		j += 8
+	}
+	// the last 3 bytes can not be loaded as 64-bit.
+	v := uint(src[42])<<16 | uint(src[43])<<8 | uint(src[44])
+	dst[56] = tab[v>>18&0x3f]
+	dst[57] = tab[v>>12&0x3f]
+	dst[58] = tab[v>>6&0x3f]
+	dst[59] = tab[v&0x3f]
+}
+
 // lineEncode encode max 45 bytes data into uuconded data.
 func lineEncode(dst []byte, src []byte, n int, useGrave bool) {
 	r := n % 3
@@ -2,6 +2,7 @@ package uuencode_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -480,6 +481,109 @@ func TestMultiDecodeReadClose(t *testing.T) {
 	}
 }
 
+func TestMultiDecodeTinyDst(t *testing.T) {
+	src := bytes.Repeat([]byte("uuencoded text line\n"), 400)
+	uucontent, _, err := transform.Bytes(uuencode.Uue.NewEncoder(), src)
+	if err != nil {
+		t.Fatal("err at encoding:", err)
+	}
+	for eol, want := range map[string][]byte{
+		"":     src,
+		"\r\n": bytes.ReplaceAll(src, []byte("\n"), []byte("\r\n")),
+	} {
+		d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithTextEOL(eol))
+		got := make(chan []byte, 1)
+		go func() {
+			for r := range ch {
+				b, _ := ioutil.ReadAll(r)
+				got <- b
+			}
+		}()
+		// the decoded bytes go to the pipe, so a tiny dst never stalls.
+		dst := make([]byte, 1)
+		var nSrc int
+		for nSrc < len(uucontent) {
+			_, n, err := d.Transform(dst, uucontent[nSrc:], true)
+			if n == 0 {
+				t.Fatal("no progress at", nSrc, "err:", err)
+			}
+			nSrc += n
+		}
+		d.Close()
+		if diff := pretty.Compare(string(<-got), string(want)); diff != "" {
+			t.Errorf("Diff %q: %s", eol, diff)
+		}
+	}
+}
+
+func TestMultiDecodeCloseTwice(t *testing.T) {
+	d, _, ch := uuencode.NewMultiDecodeWith(uuencode.WithPipeBuffer(64))
+	type result struct {
+		b   []byte
+		err error
+	}
+	got := make(chan result, 1)
+	go func() {
+		for r := range ch {
+			b, err := ioutil.ReadAll(r)
+			got <- result{b, err}
+		}
+	}()
+	// the input stops in the middle of the uuencoded content.
+	_, _, err := d.Transform(make([]byte, 64),
+		[]byte("begin 644 a\n#0V%T\n"), false)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = d.Close(); err != nil {
+			t.Error(i, "Expecting non-error but got err:", err)
+		}
+	}
+	res := <-got
+	if string(res.b) != "Cat" || res.err != uuencode.ErrUuCancel {
+		t.Error("Got: ", string(res.b), res.err)
+	}
+}
+
+func TestMultiDecodeResetMulti(t *testing.T) {
+	d, cancel, ch := uuencode.NewMultiDecodeWith(uuencode.WithPipeBuffer(4))
+	// the first input is canceled in the middle of the uuencoded content.
+	go func() {
+		for r := range ch {
+			cancel()
+			r.Close()
+		}
+	}()
+	_, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(
+		uuencode.EncodeToString(make([]byte, tDecBigLen))), d))
+	if !errors.Is(err, uuencode.ErrUuCancel) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrUuCancel)
+	}
+	d.Close()
+	for _, in := range []string{"Cat", "Dog"} {
+		_, ch = d.ResetMulti()
+		got := make(chan string, 1)
+		go func() {
+			var s string
+			for r := range ch {
+				b, _ := ioutil.ReadAll(r)
+				s += string(b)
+			}
+			got <- s
+		}()
+		out, err := ioutil.ReadAll(transform.NewReader(bytes.NewBufferString(
+			"text\n"+uuencode.EncodeToString([]byte(in))), d))
+		d.Close()
+		if err != nil || string(out) != "text\n" {
+			t.Error("Got: ", string(out), err)
+		}
+		if s := <-got; s != in {
+			t.Error("Got: ", s, " Expecting: ", in)
+		}
+	}
+}
+
 const (
 	dummyBeginLine = "begin 666 filename.txt\n"
 	dummyEndLine   = "\n`\nend\n"
@@ -505,7 +609,7 @@ func TestDecodeLongLine(t *testing.T) {
 	_, err := ioutil.ReadAll(transform.NewReader(b, d))
 	if err == nil {
 		t.Error("Expecting error but nil error")
-	} else if err != uuencode.ErrBadLen {
+	} else if !errors.Is(err, uuencode.ErrBadLen) {
 		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadLen)
 	}
 }
@@ -534,7 +638,7 @@ func TestDecodeWrongUpperLimnitChar(t *testing.T) {
 	_, err := ioutil.ReadAll(transform.NewReader(b, d))
 	if err == nil {
 		t.Error("Expecting error but nil error")
-	} else if err != uuencode.ErrBadUUDec {
+	} else if !errors.Is(err, uuencode.ErrBadUUDec) {
 		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
 	}
 }
@@ -559,7 +663,7 @@ func TestDecodeWrongLowerLimitChar(t *testing.T) {
 	_, err := ioutil.ReadAll(transform.NewReader(b, d))
 	if err == nil {
 		t.Error("Expecting error but nil error")
-	} else if err != uuencode.ErrBadUUDec {
+	} else if !errors.Is(err, uuencode.ErrBadUUDec) {
 		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
 	}
 }
@@ -590,7 +694,7 @@ func TestDecodeVeryLongBegin(t *testing.T) {
 	_, err := ioutil.ReadAll(transform.NewReader(b, d))
 	if err == nil {
 		t.Error("Expecting error but nil error")
-	} else if err != uuencode.ErrBadLen {
+	} else if !errors.Is(err, uuencode.ErrBadLen) {
 		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadLen)
 	}
 }
@@ -621,7 +725,195 @@ func TestDecodeVeryLongWithoutBegin(t *testing.T) {
 	_, err := ioutil.ReadAll(transform.NewReader(b, d))
 	if err == nil {
 		t.Error("Expecting error but nil error")
-	} else if err != uuencode.ErrBadUUDec {
+	} else if !errors.Is(err, uuencode.ErrBadUUDec) {
+		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
+	}
+}
+
+func TestEncodeToString(t *testing.T) {
+	want := "begin 644 filename\n322!L;W9E('EO=2!F;W)E=F5R+@``\n`\nend\n"
+	got := uuencode.EncodeToString([]byte("I love you forever."))
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// empty source must not output empty data line before the grave.
+	want = "begin 644 filename\n`\nend\n"
+	got = uuencode.EncodeToString(nil)
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+var tstDecodeStringSizes = []int{0, 1, 19, 45, 73, 90, 1000, 5000, 20000}
+
+func TestDecodeString(t *testing.T) {
+	for _, size := range tstDecodeStringSizes {
+		src := make([]byte, size)
+		for i := range src {
+			src[i] = byte(i * 7)
+		}
+		got, err := uuencode.DecodeString("some text\n" +
+			uuencode.EncodeToString(src) + "more text\n")
+		if err != nil {
+			t.Fatalf("size=%d expecting non-error but got err: %v", size, err)
+		}
+		if !bytes.Equal(got, src) {
+			t.Errorf("size=%d decoded bytes differ from source", size)
+		}
+	}
+}
+
+func TestDecodeStringFirstSection(t *testing.T) {
+	src1 := bytes.Repeat([]byte("first section "), 20)
+	src2 := bytes.Repeat([]byte("second section "), 20)
+	s := uuencode.EncodeToString(src1) + "text between\n" +
+		uuencode.EncodeToString(src2)
+	got, err := uuencode.DecodeString(s)
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(got), string(src1)); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// malformed content after the first section is not checked.
+	got, err = uuencode.DecodeString(uuencode.EncodeToString(src1) +
+		"begin 644 x\n!!!!!!\n")
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(got), string(src1)); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestDecodeStringCRLF(t *testing.T) {
+	src := bytes.Repeat([]byte("carriage return line feed "), 10)
+	r := transform.NewReader(bytes.NewReader(src),
+		uuencode.NewEncode(true, "\r\n"))
+	encoded, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal("err at encoding read all:", err)
+	}
+	got, err := uuencode.DecodeString(string(encoded))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if diff := pretty.Compare(string(got), string(src)); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestDecodeStringFail(t *testing.T) {
+	_, err := uuencode.DecodeString("no uuencoded content\n")
+	if err != uuencode.ErrBadUUDec {
 		t.Error("Got: ", err, " Expecting: ", uuencode.ErrBadUUDec)
 	}
+	_, err = uuencode.DecodeString("begin 644 file.txt\n#0V%T\n")
+	if err == nil {
+		t.Error("Expecting error but nil error")
+	}
+	_, err = uuencode.DecodeString("begin 644 file.txt\n!!!!!!\n`\nend\n")
+	if err == nil {
+		t.Error("Expecting error but nil error")
+	}
+}
+
+var tstTerminationData = []struct {
+	name, in, text string
+}{
+	{name: "lf", in: "begin 644 f\n#0V%T\n`\nend\n"},
+	{name: "no final lf", in: "begin 644 f\n#0V%T\n`\nend"},
+	{name: "crlf", in: "begin 644 f\r\n#0V%T\r\n`\r\nend\r\n"},
+	{name: "crlf no final lf", in: "begin 644 f\r\n#0V%T\r\n`\r\nend\r"},
+	{name: "crlf no final crlf", in: "begin 644 f\r\n#0V%T\r\n`\r\nend"},
+	{name: "text no final lf", in: "begin 644 f\n#0V%T\n`\nend\ntext",
+		text: "text"},
+	{name: "leading text", in: "text\nbegin 644 f\n#0V%T\n`\nend",
+		text: "text\n"},
+}
+
+// tstWriteByByte writes src byte by byte into a transform.Writer of t.
+func tstWriteByByte(t transform.Transformer, src string) ([]byte, error) {
+	b := new(bytes.Buffer)
+	w := transform.NewWriter(b, t)
+	for i := 0; i < len(src); i++ {
+		if _, err := w.Write([]byte{src[i]}); err != nil {
+			return nil, err
+		}
+	}
+	err := w.Close()
+	return b.Bytes(), err
+}
+
+func TestDecodeTermination(t *testing.T) {
+	for _, d := range tstTerminationData {
+		want := "Cat"
+		if d.text != "" {
+			if d.name == "leading text" {
+				want = d.text + want
+			} else {
+				want += d.text
+			}
+		}
+		got, err := ioutil.ReadAll(uuencode.NewReader(
+			bytes.NewBufferString(d.in)))
+		if err != nil || string(got) != want {
+			t.Errorf("%s reader: Want: %q\n Got: %q %v", d.name, want, got,
+				err)
+		}
+		got, err = tstWriteByByte(uuencode.NewDecode(), d.in)
+		if err != nil || string(got) != want {
+			t.Errorf("%s writer: Want: %q\n Got: %q %v", d.name, want, got,
+				err)
+		}
+		s, _, err := transform.String(uuencode.NewDecode(), d.in)
+		if err != nil || s != want {
+			t.Errorf("%s string: Want: %q\n Got: %q %v", d.name, want, s, err)
+		}
+		decoded, err := uuencode.DecodeString(d.in)
+		if err != nil || string(decoded) != "Cat" {
+			t.Errorf("%s multi: Want: %q\n Got: %q %v", d.name, "Cat", decoded,
+				err)
+		}
+		dec, _, ch := uuencode.NewMultiDecode()
+		go func() {
+			for r := range ch {
+				io.Copy(ioutil.Discard, r)
+			}
+		}()
+		got, err = tstWriteByByte(dec, d.in)
+		dec.Close()
+		if err != nil || string(got) != d.text {
+			t.Errorf("%s multi writer: Want: %q\n Got: %q %v", d.name, d.text,
+				got, err)
+		}
+	}
+}
+
+var tstMissingEndData = []string{
+	"begin 644 f\n#0V%T\n",
+	"begin 644 f\n#0V%T",
+	"begin 644 f\n#0V%T\n`",
+	"begin 644 f\n#0V%T\n`\n",
+	"begin 644 f\n#0V%T\n`\nen",
+	"begin 644 f\r\n#0V%T\r\n`\r\n",
+	"begin 644 f",
+}
+
+func TestDecodeMissingEnd(t *testing.T) {
+	for _, in := range tstMissingEndData {
+		var merr *uuencode.MissingEndError
+		_, err := ioutil.ReadAll(uuencode.NewReader(bytes.NewBufferString(in)))
+		if !errors.As(err, &merr) {
+			t.Errorf("%q reader: Expecting MissingEndError but got %v", in, err)
+		}
+		_, err = tstWriteByByte(uuencode.NewDecode(), in)
+		if !errors.As(err, &merr) {
+			t.Errorf("%q writer: Expecting MissingEndError but got %v", in, err)
+		}
+		_, err = uuencode.DecodeString(in)
+		if !errors.As(err, &merr) {
+			t.Errorf("%q multi: Expecting MissingEndError but got %v", in, err)
+		}
+	}
 }
@@ -0,0 +1,239 @@
+// Package uufs serves the uuencoded contents of an archive as a read-only
+// io/fs.FS, eg: for http.FileServer or fs.WalkDir.
+package uufs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	uu "github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+// errNoSize indicates the size of io.ReaderAt can not be found.
+var errNoSize = errors.New("uufs: unknown size of reader")
+
+// uuFS is fs.FS of the uuencoded contents. All files are in the root
+// directory.
+type uuFS struct {
+	r      io.ReaderAt
+	files  []*fileInfo
+	byName map[string]*fileInfo
+}
+
+// fileInfo is fs.FileInfo and fs.DirEntry of one uuencoded content.
+type fileInfo struct {
+	name string
+	mode fs.FileMode
+	size int64
+	sec  uu.Section
+}
+
+func (fi *fileInfo) Name() string               { return fi.name }
+func (fi *fileInfo) Size() int64                { return fi.size }
+func (fi *fileInfo) Mode() fs.FileMode          { return fi.mode }
+func (fi *fileInfo) ModTime() time.Time         { return time.Time{} }
+func (fi *fileInfo) IsDir() bool                { return fi.mode.IsDir() }
+func (fi *fileInfo) Sys() interface{}           { return nil }
+func (fi *fileInfo) Type() fs.FileMode          { return fi.mode.Type() }
+func (fi *fileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+// New returns fs.FS exposing every uuencoded content of r as a file named and
+// permitted by its begin line. r must have Size method, eg: bytes.Reader, or
+// Stat method, eg: os.File. The directory part of the names is stripped, the
+// name that is still not valid, eg: "..", is replaced by uu_1, uu_2 and so on,
+// and the content sharing the name of the earlier one is renamed, eg:
+// file(1).txt. Every
+// content is decoded once to find its size and to verify its trailer lines.
+// The contents without end marker line are not exposed.
+func New(r io.ReaderAt) (fs.FS, error) {
+	size, err := readerSize(r)
+	if err != nil {
+		return nil, err
+	}
+	secs, err := uu.FindUuencode(r, size)
+	if err != nil {
+		return nil, err
+	}
+	f := &uuFS{r: r, byName: make(map[string]*fileInfo)}
+	var unnamed int
+	for _, sec := range secs {
+		fi := &fileInfo{mode: sec.Header.Mode, sec: sec}
+		if fi.mode == 0 {
+			fi.mode = 0644
+		}
+		// the trailer lines follow the section.
+		p, err := uu.NewPartReader(io.NewSectionReader(r, sec.Offset,
+			size-sec.Offset)).NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if fi.size, err = io.Copy(ioutil.Discard, p); err != nil {
+			return nil, err
+		}
+		name := path.Base(strings.Replace(sec.Header.Name, "\\", "/", -1))
+		if !fs.ValidPath(name) || name == "." || name == "/" {
+			unnamed++
+			name = fmt.Sprintf("uu_%d", unnamed)
+		}
+		fi.name = name
+		ext := path.Ext(name)
+		for i := 1; f.byName[fi.name] != nil; i++ {
+			fi.name = fmt.Sprintf("%s(%d)%s", strings.TrimSuffix(name, ext), i,
+				ext)
+		}
+		f.files = append(f.files, fi)
+		f.byName[fi.name] = fi
+	}
+	return f, nil
+}
+
+// readerSize returns the size of r.
+func readerSize(r io.ReaderAt) (int64, error) {
+	switch r := r.(type) {
+	case interface{ Size() int64 }:
+		return r.Size(), nil
+	case interface{ Stat() (os.FileInfo, error) }:
+		fi, err := r.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return fi.Size(), nil
+	}
+	return 0, errNoSize
+}
+
+// decoder returns the reader of the decoded content of fi.
+func (f *uuFS) decoder(fi *fileInfo) io.Reader {
+	sr := io.NewSectionReader(f.r, fi.sec.Offset, fi.sec.Length)
+	return transform.NewReader(sr, uu.NewDecode())
+}
+
+// Open implements fs.FS.
+func (f *uuFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	} else if name == "." {
+		return &dir{fs: f}, nil
+	}
+	fi := f.byName[name]
+	if fi == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &file{fs: f, fi: fi}, nil
+}
+
+// file is the opened uuencoded content. It decodes lazily and seeking
+// backward restarts the decoding.
+type file struct {
+	fs *uuFS
+	fi *fileInfo
+	// pos is the read position and rd decodes from rdPos.
+	pos, rdPos int64
+	rd         io.Reader
+	closed     bool
+}
+
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.fi, nil
+}
+
+func (f *file) Read(b []byte) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	} else if f.pos >= f.fi.size {
+		return 0, io.EOF
+	}
+	if f.rd == nil || f.rdPos > f.pos {
+		f.rd, f.rdPos = f.fs.decoder(f.fi), 0
+	}
+	if f.rdPos < f.pos {
+		n, err := io.CopyN(ioutil.Discard, f.rd, f.pos-f.rdPos)
+		f.rdPos += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.rd.Read(b)
+	f.pos += int64(n)
+	f.rdPos = f.pos
+	return n, err
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += f.fi.size
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.fi.name,
+			Err: fs.ErrInvalid}
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *file) Close() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.closed = true
+	return nil
+}
+
+// dir is the opened root directory.
+type dir struct {
+	fs *uuFS
+	// off is the number of entries read by ReadDir.
+	off int
+}
+
+// dirInfo is fs.FileInfo of the root directory.
+type dirInfo struct{}
+
+func (dirInfo) Name() string       { return "." }
+func (dirInfo) Size() int64        { return 0 }
+func (dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (dirInfo) ModTime() time.Time { return time.Time{} }
+func (dirInfo) IsDir() bool        { return true }
+func (dirInfo) Sys() interface{}   { return nil }
+
+func (d *dir) Stat() (fs.FileInfo, error) {
+	return dirInfo{}, nil
+}
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fs.ErrInvalid}
+}
+
+func (d *dir) Close() error {
+	return nil
+}
+
+// ReadDir implements fs.ReadDirFile. The entries are in input order.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.fs.files[d.off:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	} else if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	entries := make([]fs.DirEntry, len(rest))
+	for i, fi := range rest {
+		entries[i] = fi
+	}
+	d.off += len(rest)
+	return entries, nil
+}
@@ -0,0 +1,89 @@
+package uufs_test
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode/uufs"
+)
+
+const archive = "junk\nbegin 600 dir/a.txt\n#86)C\n`\nend\n" +
+	"begin 644 b.txt\n&86)C9&5F\n`\nend\nbegin 755 a.txt\n#9&5F\n`\nend\n" +
+	"begin 644 ..\n#86)C\n`\nend\nbegin 644 broken.txt\n#86)C\n"
+
+func TestNew(t *testing.T) {
+	f, err := uufs.New(strings.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fstest.TestFS(f, "a.txt", "b.txt", "a(1).txt", "uu_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	err = fs.WalkDir(f, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(f, p)
+		info, _ := d.Info()
+		got = append(got, p+" "+info.Mode().String()+" "+string(b))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"a(1).txt -rwxr-xr-x def",
+		"a.txt -rw------- abc",
+		"b.txt -rw-r--r-- abcdef",
+		"uu_1 -rw-r--r-- abc",
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestNewFail(t *testing.T) {
+	if _, err := uufs.New(struct{ io.ReaderAt }{
+		strings.NewReader(archive)}); err == nil {
+		t.Error("Expected error but return nil")
+	}
+	for _, bad := range []string{
+		"begin 644 a.txt\n#86)C\nbad line\n`\nend\n",
+		"begin 644 a.txt\n#86)C\n`\nend\ncrc32 00000000\n",
+	} {
+		if _, err := uufs.New(bytes.NewReader([]byte(bad))); err == nil {
+			t.Errorf("%q Expected error but return nil", bad)
+		}
+	}
+}
+
+func TestFileServer(t *testing.T) {
+	f, err := uufs.New(strings.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.FileServer(http.FS(f)))
+	defer srv.Close()
+	req, _ := http.NewRequest("GET", srv.URL+"/b.txt", nil)
+	req.Header.Set("Range", "bytes=2-4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusPartialContent ||
+		string(b) != "cde" {
+		t.Errorf("Got: %d %q %v", resp.StatusCode, b, err)
+	}
+}
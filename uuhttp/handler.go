@@ -0,0 +1,99 @@
+// Package uuhttp decodes uuencoded HTTP request bodies, for legacy clients
+// posting uuencoded payloads.
+package uuhttp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/sanylcs/uuencode/uumail"
+)
+
+// sniffLen is the number of bytes peeked to find the begin line.
+const sniffLen = 512
+
+// Option configures the handler created by Handler.
+type Option func(*handler)
+
+// WithSniff sets whether the request body that starts with a begin line is
+// decoded even if its headers do not declare uuencode.
+func WithSniff(sniff bool) Option {
+	return func(h *handler) {
+		h.sniff = sniff
+	}
+}
+
+type handler struct {
+	next  http.Handler
+	sniff bool
+}
+
+// body is the decoding request body that closes the original body.
+type body struct {
+	io.Reader
+	io.Closer
+}
+
+// Handler returns http.Handler that replaces the uuencoded request body with
+// the decoded one before calling next. The body is uuencoded if
+// Content-Transfer-Encoding is uuencode, eg: x-uuencode, or Content-Type is
+// one of text/x-uuencode, application/x-uuencode or application/x-uue. Both
+// headers are then replaced and the content length becomes unknown.
+func Handler(next http.Handler, opts ...Option) http.Handler {
+	h := &handler{next: next}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	declared := uumail.IsUuencoded(textproto.MIMEHeader(r.Header)) ||
+		isUuencodeType(r.Header.Get("Content-Type"))
+	if r.Body == nil || r.Body == http.NoBody || !declared && !h.sniff {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	br := bufio.NewReader(r.Body)
+	if !declared && !(h.sniff && startsWithBegin(br)) {
+		r.Body = body{br, r.Body}
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.Header = r.Header.Clone()
+	r2.Header.Del("Content-Transfer-Encoding")
+	r2.Header.Del("Content-Length")
+	if isUuencodeType(r2.Header.Get("Content-Type")) {
+		r2.Header.Set("Content-Type", "application/octet-stream")
+	}
+	r2.ContentLength = -1
+	r2.Body = body{uumail.NewDecodeReader(br), r.Body}
+	h.next.ServeHTTP(w, r2)
+}
+
+// isUuencodeType reports whether the media type of ct is uuencode.
+func isUuencodeType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(mt) {
+	case "text/x-uuencode", "application/x-uuencode", "application/x-uue":
+		return true
+	}
+	return false
+}
+
+// startsWithBegin reports whether br starts with a begin line after blank
+// lines.
+func startsWithBegin(br *bufio.Reader) bool {
+	b, _ := br.Peek(sniffLen)
+	return bytes.HasPrefix(bytes.TrimLeft(b, " \t\r\n"), []byte("begin "))
+}
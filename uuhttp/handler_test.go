@@ -0,0 +1,59 @@
+package uuhttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sanylcs/uuencode/uuhttp"
+)
+
+func TestHandler(t *testing.T) {
+	framed := "begin 644 a.txt\n#86)C\n`\nend\n"
+	tsts := []struct {
+		header http.Header
+		body   string
+		sniff  bool
+		want   string
+		ct     string
+	}{
+		{nil, "plain", false, "plain", ""},
+		{nil, framed, false, framed, ""},
+		{nil, framed, true, "abc", ""},
+		{http.Header{"Content-Transfer-Encoding": {"x-uuencode"}}, framed,
+			false, "abc", ""},
+		{http.Header{"Content-Transfer-Encoding": {"x-uue"}}, "#86)C\n",
+			false, "abc", ""},
+		{http.Header{"Content-Type": {"application/x-uuencode"}}, framed,
+			false, "abc", "application/octet-stream"},
+		{http.Header{"Content-Type": {"text/plain"}}, framed, false, framed,
+			"text/plain"},
+	}
+	for i, tst := range tsts {
+		var got, ct, cte string
+		var length int64
+		h := uuhttp.Handler(http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ct = string(b), r.Header.Get("Content-Type")
+			cte, length = r.Header.Get("Content-Transfer-Encoding"),
+				r.ContentLength
+		}), uuhttp.WithSniff(tst.sniff))
+		r := httptest.NewRequest("POST", "/", strings.NewReader(tst.body))
+		for k, v := range tst.header {
+			r.Header[k] = v
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if got != tst.want || ct != tst.ct || cte != "" && got != tst.body {
+			t.Errorf("%d Got: %q %q %q", i, got, ct, cte)
+		}
+		if got != tst.body && length != -1 {
+			t.Errorf("%d Got content length: %d", i, length)
+		}
+	}
+}
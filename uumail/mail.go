@@ -0,0 +1,62 @@
+// Package uumail extracts uuencoded attachments from mail messages, which is
+// common in pre-MIME mail.
+package uumail
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/mail"
+
+	uu "github.com/sanylcs/uuencode"
+)
+
+// Attachment is one decoded uuencoded content of the message body.
+type Attachment struct {
+	// Header is the parsed begin line of the uuencoded content.
+	Header uu.Header
+	// Data is the decoded content.
+	Data []byte
+}
+
+// Reader returns the reader of the decoded content.
+func (a *Attachment) Reader() io.Reader {
+	return bytes.NewReader(a.Data)
+}
+
+// Message is the mail message separated into its text and attachments.
+type Message struct {
+	// Header is the mail header.
+	Header mail.Header
+	// Text is the body without the uuencoded contents.
+	Text []byte
+	// Attachments are the uuencoded contents in body order.
+	Attachments []Attachment
+}
+
+// ExtractMessage reads the body of msg and separates the uuencoded contents
+// from the text. The body is consumed. On decoding error, the message
+// extracted so far is returned with the error.
+func ExtractMessage(msg *mail.Message) (*Message, error) {
+	m := &Message{Header: msg.Header}
+	s := uu.NewSplitter(msg.Body)
+	for {
+		seg, err := s.Next()
+		if err == io.EOF {
+			return m, nil
+		} else if err != nil {
+			return m, err
+		}
+		switch seg := seg.(type) {
+		case uu.TextSegment:
+			m.Text = append(m.Text, seg.Text...)
+		case *uu.Part:
+			b, err := ioutil.ReadAll(seg)
+			if err != nil {
+				return m, err
+			}
+			m.Attachments = append(m.Attachments, Attachment{
+				Header: seg.Header, Data: b})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package uumail_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	uu "github.com/sanylcs/uuencode"
+	"github.com/sanylcs/uuencode/uumail"
+)
+
+func TestExtractMessage(t *testing.T) {
+	in := "From: a@example.com\nSubject: files\n\nHello,\n\n" +
+		"begin 644 a.txt\n#86)C\n`\nend\nand\nbegin 600 b.txt\n&86)C9&5F\n`\n" +
+		"end\nbye\n"
+	msg, err := mail.ReadMessage(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := uumail.ExtractMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Header.Get("Subject") != "files" {
+		t.Error("Got header: ", m.Header)
+	}
+	want := "Hello,\n\nand\nbye\n"
+	if diff := pretty.Compare(string(m.Text), want); diff != "" {
+		t.Error(diff)
+	}
+	var got []string
+	for _, a := range m.Attachments {
+		b, err := ioutil.ReadAll(a.Reader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, a.Header.Name+": "+string(b))
+	}
+	if diff := pretty.Compare(got, []string{"a.txt: abc",
+		"b.txt: abcdef"}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestExtractMessageFail(t *testing.T) {
+	in := "Subject: broken\n\nHello,\nbegin 644 a.txt\n#86)C\n`\nend\n" +
+		"begin 644 b.txt\n#86)C\n"
+	msg, err := mail.ReadMessage(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := uumail.ExtractMessage(msg)
+	if !errors.Is(err, uu.ErrBadUUDec) || m == nil ||
+		len(m.Attachments) != 1 || string(m.Text) != "Hello,\n" {
+		t.Errorf("Got: %+v %v", m, err)
+	}
+}
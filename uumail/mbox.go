@@ -0,0 +1,112 @@
+package uumail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/mail"
+)
+
+// MboxReader iterates the messages of mbox stream.
+type MboxReader struct {
+	br    *bufio.Reader
+	index int
+	// blank is true if the last read line is blank or nothing is read.
+	blank   bool
+	started bool
+	err     error
+	unstuff bool
+}
+
+// MboxOption configures MboxReader created by NewMboxReader.
+type MboxOption func(*MboxReader)
+
+// WithDotStuffing sets whether the lines starting with ".." are SMTP dot
+// stuffed lines to be unstuffed, for mailbox written without removing it.
+func WithDotStuffing(stuffed bool) MboxOption {
+	return func(mr *MboxReader) {
+		mr.unstuff = stuffed
+	}
+}
+
+// NewMboxReader returns MboxReader that reads the mbox stream r.
+func NewMboxReader(r io.Reader, opts ...MboxOption) *MboxReader {
+	mr := &MboxReader{br: bufio.NewReader(r), blank: true}
+	for _, opt := range opts {
+		opt(mr)
+	}
+	return mr
+}
+
+// MboxMessage is one message of mbox.
+type MboxMessage struct {
+	// Index is the position of the message in mbox, starts from 0.
+	Index int
+	*Message
+}
+
+// NextRaw returns the index and bytes of the next message, without the "From "
+// separator line. The escaped ">From " lines are unescaped. It returns io.EOF
+// when there is no more message.
+func (mr *MboxReader) NextRaw() (int, []byte, error) {
+	var msg []byte
+	for mr.err == nil {
+		var line []byte
+		line, mr.err = mr.br.ReadBytes('\n')
+		if len(line) == 0 {
+			break
+		}
+		isFrom := mr.blank && bytes.HasPrefix(line, []byte("From "))
+		mr.blank = len(bytes.TrimRight(line, "\r\n")) == 0
+		if isFrom {
+			if mr.started || len(msg) > 0 {
+				mr.started = true
+				return mr.take(msg)
+			}
+			mr.started = true
+			continue
+		}
+		if isEscapedFrom(line) || mr.unstuff &&
+			bytes.HasPrefix(line, []byte("..")) {
+			line = line[1:]
+		}
+		msg = append(msg, line...)
+	}
+	if mr.err != io.EOF {
+		return mr.index, nil, mr.err
+	} else if mr.started || len(msg) > 0 {
+		mr.started = false
+		return mr.take(msg)
+	}
+	return mr.index, nil, io.EOF
+}
+
+// take returns msg as the current message and advances the index.
+func (mr *MboxReader) take(msg []byte) (int, []byte, error) {
+	mr.index++
+	return mr.index - 1, msg, nil
+}
+
+// Next returns the next message with its uuencoded attachments extracted by
+// ExtractMessage. On the error of the message, the returned MboxMessage still
+// has its Index and the iteration can continue. It returns io.EOF when there
+// is no more message.
+func (mr *MboxReader) Next() (*MboxMessage, error) {
+	index, raw, err := mr.NextRaw()
+	if err != nil {
+		return nil, err
+	}
+	mm := &MboxMessage{Index: index}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return mm, err
+	}
+	mm.Message, err = ExtractMessage(msg)
+	return mm, err
+}
+
+// isEscapedFrom reports whether line is ">From ", ">>From " and so on.
+func isEscapedFrom(line []byte) bool {
+	t := bytes.TrimLeft(line, ">")
+	return len(t) < len(line) && bytes.HasPrefix(t, []byte("From "))
+}
@@ -0,0 +1,84 @@
+package uumail_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode/uumail"
+)
+
+func TestMboxReaderNextRaw(t *testing.T) {
+	in := "From a@example.com Mon Jan  1 00:00:00 2001\nSubject: 1\n\n" +
+		">From here\n>>From there\n>Fromage\n..dot\nFrom inline\n\n" +
+		"From b@example.com Mon Jan  1 00:00:00 2001\nSubject: 2\n\nbody\n\n" +
+		"From c@example.com Mon Jan  1 00:00:00 2001\n"
+	tsts := []struct {
+		opts []uumail.MboxOption
+		want []string
+	}{
+		{nil, []string{
+			"Subject: 1\n\nFrom here\n>From there\n>Fromage\n..dot\n" +
+				"From inline\n\n",
+			"Subject: 2\n\nbody\n\n",
+			"",
+		}},
+		{[]uumail.MboxOption{uumail.WithDotStuffing(true)}, []string{
+			"Subject: 1\n\nFrom here\n>From there\n>Fromage\n.dot\n" +
+				"From inline\n\n",
+			"Subject: 2\n\nbody\n\n",
+			"",
+		}},
+	}
+	for _, tst := range tsts {
+		mr := uumail.NewMboxReader(strings.NewReader(in), tst.opts...)
+		var got []string
+		for {
+			i, msg, err := mr.NextRaw()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			} else if i != len(got) {
+				t.Errorf("Got index %d", i)
+			}
+			got = append(got, string(msg))
+		}
+		if diff := pretty.Compare(got, tst.want); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestMboxReaderNext(t *testing.T) {
+	in := "From a@example.com Mon Jan  1 00:00:00 2001\nSubject: 1\n\n" +
+		"Hello\nbegin 644 a.txt\n#86)C\n`\nend\n\n" +
+		"From b@example.com Mon Jan  1 00:00:00 2001\nno header line\n\n" +
+		"From c@example.com Mon Jan  1 00:00:00 2001\nSubject: 3\n\n" +
+		"begin 600 b.txt\n&86)C9&5F\n`\nend\n"
+	mr := uumail.NewMboxReader(strings.NewReader(in),
+		uumail.WithDotStuffing(true))
+	var got []string
+	for {
+		mm, err := mr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			if mm == nil || mm.Index != 1 {
+				t.Fatal(mm, err)
+			}
+			got = append(got, "error")
+			continue
+		}
+		s := mm.Header.Get("Subject") + ": " + string(mm.Text)
+		for _, a := range mm.Attachments {
+			s += a.Header.Name + "=" + string(a.Data)
+		}
+		got = append(got, s)
+	}
+	want := []string{"1: Hello\n\na.txt=abc", "error", "3: b.txt=abcdef"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
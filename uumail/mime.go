@@ -0,0 +1,60 @@
+package uumail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	uu "github.com/sanylcs/uuencode"
+	"golang.org/x/text/transform"
+)
+
+// framePeek is the number of bytes peeked to find the begin line.
+const framePeek = 512
+
+// IsUuencoded reports whether Content-Transfer-Encoding of h is uuencode, eg:
+// x-uuencode or x-uue.
+func IsUuencoded(h textproto.MIMEHeader) bool {
+	switch strings.ToLower(strings.TrimSpace(
+		h.Get("Content-Transfer-Encoding"))) {
+	case "x-uuencode", "x-uue", "uuencode", "x-uu":
+		return true
+	}
+	return false
+}
+
+// DecodePart returns the reader of the decoded body of p if p is uuencoded as
+// reported by IsUuencoded, or p itself otherwise. The body can either be
+// framed by the begin and end marker lines, or be the uuencoded data lines
+// only.
+func DecodePart(p *multipart.Part) io.Reader {
+	if !IsUuencoded(p.Header) {
+		return p
+	}
+	return NewDecodeReader(p)
+}
+
+// NewDecodeReader returns the reader of the decoded uuencoded body r, which is
+// either framed by the begin and end marker lines, or the uuencoded data lines
+// only.
+func NewDecodeReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	// skip the leading blank lines which end the bare data lines.
+	for {
+		b, _ := br.Peek(framePeek)
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 || len(bytes.TrimSpace(b[:i])) > 0 {
+			break
+		}
+		br.Discard(i + 1)
+	}
+	b, _ := br.Peek(framePeek)
+	if bytes.HasPrefix(b, []byte("begin ")) ||
+		bytes.HasPrefix(b, []byte("table")) {
+		return transform.NewReader(br, uu.NewDecode())
+	}
+	return transform.NewReader(br, uu.NewBodyDecode())
+}
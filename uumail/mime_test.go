@@ -0,0 +1,57 @@
+package uumail_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode/uumail"
+)
+
+func TestDecodePart(t *testing.T) {
+	parts := []struct {
+		cte, body string
+	}{
+		{"", "plain text"},
+		{"x-uuencode", "begin 644 a.txt\n#86)C\n`\nend\n"},
+		{"X-UUE", "\n&86)C9&5F\n`\n"},
+		{"x-uue", "&86)C9&5F\n#86)C\n"},
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		if p.cte != "" {
+			h.Set("Content-Transfer-Encoding", p.cte)
+		}
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, p.body)
+	}
+	mw.Close()
+	mr := multipart.NewReader(&buf, mw.Boundary())
+	var got []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(uumail.DecodePart(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(b))
+	}
+	want := []string{"plain text", "abc", "abcdef", "abcdefabc"}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,111 @@
+package uuutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	uu "github.com/sanylcs/uuencode"
+)
+
+// DiffKind is how a section differs between the streams compared by Compare.
+type DiffKind int
+
+const (
+	// DiffOnlyA reports the section is only in the first stream.
+	DiffOnlyA DiffKind = iota
+	// DiffOnlyB reports the section is only in the second stream.
+	DiffOnlyB
+	// DiffSize reports the decoded contents have different sizes.
+	DiffSize
+	// DiffContent reports the decoded contents have the same size but
+	// different SHA-256.
+	DiffContent
+)
+
+// SectionSum summarizes the decoded content of one uuencoded content.
+type SectionSum struct {
+	// Size is the number of decoded bytes.
+	Size int64
+	// SHA256 is the hex SHA-256 of the decoded bytes.
+	SHA256 string
+}
+
+// Difference is one section that differs between the streams compared by
+// Compare.
+type Difference struct {
+	// Name is the begin line file name of the section.
+	Name string
+	Kind DiffKind
+	// A and B are the section of the first and the second stream. One of them
+	// is nil for DiffOnlyA and DiffOnlyB.
+	A, B *SectionSum
+}
+
+// Compare decodes the uuencoded contents of a and b and returns the sections
+// that differ by name, size or content hash, eg: to verify two independently
+// produced encodings of the same files. Sections are matched by file name, the
+// n-th section of a name in a is matched with the n-th one in b. The
+// differences are in the order of a, followed by the sections only in b in the
+// order of b. It returns no difference if both have the same sections.
+func Compare(a, b io.Reader) ([]Difference, error) {
+	names, sa, err := sumSections(a)
+	if err != nil {
+		return nil, err
+	}
+	namesB, sb, err := sumSections(b)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []Difference
+	for _, name := range names {
+		s := sa[name][0]
+		sa[name] = sa[name][1:]
+		if len(sb[name]) == 0 {
+			diffs = append(diffs, Difference{Name: name, Kind: DiffOnlyA,
+				A: s})
+			continue
+		}
+		t := sb[name][0]
+		sb[name] = sb[name][1:]
+		if s.Size != t.Size {
+			diffs = append(diffs, Difference{Name: name, Kind: DiffSize,
+				A: s, B: t})
+		} else if s.SHA256 != t.SHA256 {
+			diffs = append(diffs, Difference{Name: name, Kind: DiffContent,
+				A: s, B: t})
+		}
+	}
+	for _, name := range namesB {
+		if len(sb[name]) > 0 {
+			diffs = append(diffs, Difference{Name: name, Kind: DiffOnlyB,
+				B: sb[name][0]})
+			sb[name] = sb[name][1:]
+		}
+	}
+	return diffs, nil
+}
+
+// sumSections returns the file names of the uuencoded contents of r in order
+// and their summaries by name.
+func sumSections(r io.Reader) ([]string, map[string][]*SectionSum, error) {
+	var names []string
+	sums := make(map[string][]*SectionSum)
+	ar := uu.NewArchiveReader(r)
+	for {
+		h, err := ar.Next()
+		if err == io.EOF {
+			return names, sums, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		hash := sha256.New()
+		n, err := io.Copy(hash, ar)
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, h.Name)
+		sums[h.Name] = append(sums[h.Name], &SectionSum{Size: n,
+			SHA256: hex.EncodeToString(hash.Sum(nil))})
+	}
+}
@@ -0,0 +1,58 @@
+package uuutil_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	uu "github.com/sanylcs/uuencode"
+	"github.com/sanylcs/uuencode/uuutil"
+)
+
+func TestCompare(t *testing.T) {
+	const (
+		abc = "begin 644 %s\n#86)C\n`\nend\n"
+		abd = "begin 644 %s\n#86)D\n`\nend\n"
+		ab  = "begin 644 %s\n\"86(`\n`\nend\n"
+	)
+	section := func(format, name string) string {
+		return strings.Replace(format, "%s", name, 1)
+	}
+	a := section(abc, "same") + section(abc, "content") +
+		section(abc, "size") + section(abc, "a-only") + "text\n" +
+		section(abc, "dup") + section(abc, "dup")
+	// the line ending and the text outside do not matter.
+	b := strings.ReplaceAll(section(abc, "same"), "\n", "\r\n") +
+		section(abd, "content") + section(ab, "size") + section(abc, "dup") +
+		section(abc, "b-only")
+	diffs, err := uuutil.Compare(strings.NewReader(a), strings.NewReader(b))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	sumABC := &uuutil.SectionSum{Size: 3, SHA256: "ba7816bf8f01cfea414140de" +
+		"5dae2223b00361a396177a9cb410ff61f20015ad"}
+	sumABD := &uuutil.SectionSum{Size: 3, SHA256: "a52d159f262b2c6ddb724a61" +
+		"840befc36eb30c88877a4030b65cbe86298449c9"}
+	sumAB := &uuutil.SectionSum{Size: 2, SHA256: "fb8e20fc2e4c3f248c60c39b" +
+		"d652f3c1347298bb977b8b4d5903b85055620603"}
+	want := []uuutil.Difference{
+		{Name: "content", Kind: uuutil.DiffContent, A: sumABC, B: sumABD},
+		{Name: "size", Kind: uuutil.DiffSize, A: sumABC, B: sumAB},
+		{Name: "a-only", Kind: uuutil.DiffOnlyA, A: sumABC},
+		{Name: "dup", Kind: uuutil.DiffOnlyA, A: sumABC},
+		{Name: "b-only", Kind: uuutil.DiffOnlyB, B: sumABC},
+	}
+	if diff := pretty.Compare(diffs, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	diffs, err = uuutil.Compare(strings.NewReader(a), strings.NewReader(a))
+	if err != nil || len(diffs) != 0 {
+		t.Error("Got: ", diffs, err)
+	}
+	_, err = uuutil.Compare(strings.NewReader(a),
+		strings.NewReader("begin 644 a\n#86)C\n"))
+	if !errors.Is(err, uu.ErrMissingEnd) {
+		t.Error("Got: ", err, " Expecting: ", uu.ErrMissingEnd)
+	}
+}
@@ -0,0 +1,97 @@
+package uuutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	uu "github.com/sanylcs/uuencode"
+)
+
+// ConcatError indicates an input of Concat is not a well-formed uuencoded
+// stream. It wraps the cause error, or uuencode.ErrBadUUDec.
+type ConcatError struct {
+	// Input is the index (starts from 0) of the failing input.
+	Input int
+	// Violation is the first problem found. It is zero if the input has no
+	// uuencoded content.
+	Violation uu.Violation
+}
+
+func (e *ConcatError) Error() string {
+	if e.Violation.Reason == "" {
+		return fmt.Sprintf("uuutil: input %d has no uuencoded content",
+			e.Input)
+	}
+	return fmt.Sprintf("uuutil: input %d is not well-formed at line %d: %s",
+		e.Input, e.Violation.Line, e.Violation.Reason)
+}
+
+// Unwrap returns the cause of failure.
+func (e *ConcatError) Unwrap() error {
+	if e.Violation.Err != nil {
+		return e.Violation.Err
+	}
+	return uu.ErrBadUUDec
+}
+
+// Concat writes rs one after another into w as a single multiple uuencoded
+// contents stream. Every input must have at least one uuencoded content and no
+// problem reported by uuencode.Validate, otherwise *ConcatError is returned
+// and nothing is written. An end of line is added to the input that does not
+// end with one.
+func Concat(w io.Writer, rs ...io.Reader) error {
+	return ConcatEOL(w, "", rs...)
+}
+
+// ConcatEOL is like Concat but also converts the end of line of every line to
+// eol, eg: "\r\n" or "\n". Empty eol keeps the end of lines as is.
+func ConcatEOL(w io.Writer, eol string, rs ...io.Reader) error {
+	if len(rs) <= 0 {
+		return errNothing
+	}
+	bs := make([][]byte, len(rs))
+	for i, r := range rs {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		rep, err := uu.Validate(bytes.NewReader(b))
+		if err != nil {
+			return err
+		} else if len(rep.Violations) > 0 {
+			return &ConcatError{Input: i, Violation: rep.Violations[0]}
+		} else if len(rep.Sections) == 0 {
+			return &ConcatError{Input: i}
+		}
+		bs[i] = b
+	}
+	for _, b := range bs {
+		if _, err := w.Write(normalizeEOL(b, eol)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeEOL returns b with the end of line of every line converted to eol.
+// The last line without end of line gets eol, or "\n" if eol is empty.
+func normalizeEOL(b []byte, eol string) []byte {
+	if eol == "" {
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			b = append(b, '\n')
+		}
+		return b
+	}
+	lines := bytes.SplitAfter(b, []byte{'\n'})
+	out := make([]byte, 0, len(b)+len(lines)*len(eol))
+	for _, l := range lines {
+		if len(l) == 0 {
+			continue
+		}
+		l = bytes.TrimSuffix(bytes.TrimSuffix(l, []byte{'\n'}), []byte{'\r'})
+		out = append(append(out, l...), eol...)
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package uuutil_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	uu "github.com/sanylcs/uuencode"
+	"github.com/sanylcs/uuencode/uuutil"
+)
+
+func TestConcat(t *testing.T) {
+	ins := []string{
+		"text\r\nbegin 644 a.txt\r\n#86)C\r\n`\r\nend\r\n",
+		"begin 600 b.txt\n#86)C\n`\nend",
+	}
+	tsts := []struct {
+		eol  string
+		want string
+	}{
+		{"", ins[0] + ins[1] + "\n"},
+		{"\n", "text\nbegin 644 a.txt\n#86)C\n`\nend\n" + ins[1] + "\n"},
+		{"\r\n", ins[0] + "begin 600 b.txt\r\n#86)C\r\n`\r\nend\r\n"},
+	}
+	for i, tst := range tsts {
+		var b bytes.Buffer
+		err := uuutil.ConcatEOL(&b, tst.eol, strings.NewReader(ins[0]),
+			strings.NewReader(ins[1]))
+		if err != nil {
+			t.Fatal(i, "Expecting non-error but got err:", err)
+		}
+		if diff := pretty.Compare(b.String(), tst.want); diff != "" {
+			t.Errorf("%d Diff: %s", i, diff)
+		}
+	}
+	// the spliced stream has every uuencoded content.
+	var b bytes.Buffer
+	if err := uuutil.Concat(&b, strings.NewReader(ins[0]),
+		strings.NewReader(ins[1])); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	rep, err := uu.Validate(&b)
+	if err != nil || len(rep.Sections) != 2 || len(rep.Violations) != 0 {
+		t.Error("Got: ", rep, err)
+	}
+}
+
+func TestConcatFail(t *testing.T) {
+	tsts := []struct {
+		in    string
+		input int
+		is    error
+	}{
+		{"begin 644 c.txt\n#86)C\n", 1, uu.ErrMissingEnd},
+		{"begin 644 c.txt\n#86)C\n\n`\nend\n", 1, uu.ErrBadUUDec},
+		{"plain text\n", 1, uu.ErrBadUUDec},
+	}
+	for i, tst := range tsts {
+		var b bytes.Buffer
+		err := uuutil.Concat(&b, strings.NewReader(uu.EncodeToString(nil)),
+			strings.NewReader(tst.in))
+		var cerr *uuutil.ConcatError
+		if !errors.As(err, &cerr) || cerr.Input != tst.input {
+			t.Errorf("%d Got: %v Expecting: *uuutil.ConcatError", i, err)
+		}
+		if !errors.Is(err, tst.is) {
+			t.Errorf("%d Got: %v Expecting: %v", i, err, tst.is)
+		}
+		if b.Len() != 0 {
+			t.Errorf("%d Got output: %q", i, b.String())
+		}
+	}
+	if err := uuutil.Concat(new(bytes.Buffer)); err == nil {
+		t.Error("Expecting error but got nil")
+	}
+}
@@ -0,0 +1,262 @@
+package uuutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	uu "github.com/sanylcs/uuencode"
+	"golang.org/x/net/context"
+	"golang.org/x/text/transform"
+)
+
+// errNothing indicates there is no input to convert.
+var errNothing = errors.New("nothing to convert")
+
+// Source is the input of ConvertReaders with the metadata outputted at the
+// begin line.
+type Source struct {
+	// Name is the file name and Mode is the permission bits.
+	Name string
+	Mode os.FileMode
+	// Reader is the content to be uuencoded.
+	Reader io.Reader
+}
+
+// ConvertReaders is like Convert but converts sources instead of files, eg:
+// HTTP uploads, archives entries or in-memory data.
+func ConvertReaders(w io.Writer, useGrave bool, eol string,
+	sources ...Source) error {
+	if len(sources) <= 0 {
+		return errNothing
+	}
+	e := uu.NewEncode(useGrave, eol)
+	for _, s := range sources {
+		if err := encodeSource(w, e, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFS is like Convert but converts the files of fsys matching patterns,
+// eg: "images/*.png", in the order of patterns then file names. The pattern
+// syntax is the same as fs.Glob. It fails if a pattern matches nothing.
+func ConvertFS(w io.Writer, useGrave bool, eol string, fsys fs.FS,
+	patterns ...string) error {
+	if len(patterns) <= 0 {
+		return errNothing
+	}
+	e := uu.NewEncode(useGrave, eol)
+	for _, pattern := range patterns {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		} else if len(names) == 0 {
+			return fmt.Errorf("uuutil: no file matches %q", pattern)
+		}
+		for _, name := range names {
+			if err = encodeFSFile(w, e, fsys, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeFSFile writes the uuencoded file name of fsys into w using e.
+func encodeFSFile(w io.Writer, e *uu.Encode, fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err = encodeSource(w, e, Source{Name: fi.Name(), Mode: fi.Mode(),
+		Reader: f}); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ConvertParallel is like Convert but encodes up to workers files concurrently.
+// The output is written in the order of files, so it is the same as Convert.
+// At most workers encoded files are buffered in memory at once.
+func ConvertParallel(w io.Writer, useGrave bool, eol string, workers int,
+	files ...string) error {
+	if len(files) <= 0 {
+		return errNothing
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	type result struct {
+		b   bytes.Buffer
+		err error
+	}
+	results := make([]chan *result, len(files))
+	for i := range results {
+		results[i] = make(chan *result, 1)
+	}
+	// sem bounds the files being encoded or waiting to be written.
+	sem := make(chan struct{}, workers)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i, f := range files {
+			select {
+			case sem <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(f string, ch chan<- *result) {
+				r := new(result)
+				r.err = encodeFile(&r.b, useGrave, eol, f)
+				ch <- r
+			}(f, results[i])
+		}
+	}()
+	for _, ch := range results {
+		r := <-ch
+		if r.err != nil {
+			return r.err
+		}
+		if _, err := w.Write(r.b.Bytes()); err != nil {
+			return err
+		}
+		<-sem
+	}
+	return nil
+}
+
+// ConvertContext is like Convert but stops between and within files when ctx
+// is done, and returns ctx.Err(). ctx is checked before every write into w, so
+// a Write blocked in w delays the return until it returns, eg: unblock w by
+// closing the pipe or connection. w is not used after ConvertContext returns.
+func ConvertContext(ctx context.Context, w io.Writer, useGrave bool,
+	eol string, files ...string) error {
+	if len(files) <= 0 {
+		return errNothing
+	}
+	cw := &ctxWriter{ctx: ctx, w: w}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := encodeFile(cw, useGrave, eol, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ctxWriter is io.Writer that fails once ctx is done.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw *ctxWriter) Write(b []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(b)
+}
+
+// ConvertEach is like Convert but writes each file into its own writer, eg:
+// one .uu file per input. create is called with the file name of each input
+// and the writer is closed after the file is written.
+func ConvertEach(create func(name string) (io.WriteCloser, error),
+	useGrave bool, eol string, files ...string) error {
+	if len(files) <= 0 {
+		return errNothing
+	}
+	for _, f := range files {
+		w, err := create(filepath.Base(f))
+		if err != nil {
+			return err
+		}
+		if err = encodeFile(w, useGrave, eol, f); err != nil {
+			w.Close()
+			return err
+		}
+		if err = w.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertSplit is like Convert but splits the uuencoded output of each file
+// into messages of at most maxBytes, eg: mail size caps. Lines are never broken
+// and each message starts with "section i of n of file name" banner line. next
+// is called for the writer of message i of n (starts from 1) of the file name,
+// the writer is closed after the message is written. uuencode.Assembler
+// reassembles the messages.
+func ConvertSplit(next func(name string, i, n int) (io.WriteCloser, error),
+	useGrave bool, eol string, maxBytes int, files ...string) error {
+	if len(files) <= 0 {
+		return errNothing
+	}
+	e := uu.NewEncode(useGrave, eol)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		fi, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		e.ResetFile(fi.Mode(), fi.Name())
+		parts, err := uu.SplitEncode(b, maxBytes, true, e)
+		if err != nil {
+			return err
+		}
+		for i, p := range parts {
+			w, err := next(fi.Name(), i+1, len(parts))
+			if err != nil {
+				return err
+			}
+			if _, err = w.Write(p); err != nil {
+				w.Close()
+				return err
+			}
+			if err = w.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeFile writes the uuencoded file f into w.
+func encodeFile(w io.Writer, useGrave bool, eol string, f string) error {
+	rc, err := os.Open(f)
+	if err != nil {
+		return err
+	}
+	err = uu.EncodeFile(w, rc, uu.WithGravePadding(useGrave), uu.WithEOL(eol))
+	if err != nil {
+		rc.Close()
+		return err
+	}
+	// close and release the file contents.
+	return rc.Close()
+}
+
+// encodeSource writes the uuencoded s into w using e.
+func encodeSource(w io.Writer, e *uu.Encode, s Source) error {
+	e.ResetFile(s.Mode, s.Name)
+	// write the converted result into w which is provided by caller.
+	_, err := io.Copy(w, transform.NewReader(s.Reader, e))
+	return err
+}
@@ -0,0 +1,251 @@
+package uuutil_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	uu "github.com/sanylcs/uuencode"
+	"github.com/sanylcs/uuencode/uuutil"
+	"golang.org/x/net/context"
+)
+
+func TestConvertReaders(t *testing.T) {
+	b := new(bytes.Buffer)
+	err := uuutil.ConvertReaders(b, true, "\n",
+		uuutil.Source{Name: "a.txt", Mode: 0600,
+			Reader: strings.NewReader("abc")},
+		uuutil.Source{Name: "b.txt", Mode: 0755,
+			Reader: strings.NewReader("abcdef")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "begin 600 a.txt\n#86)C\n`\nend\nbegin 755 b.txt\n&86)C9&5F\n`\nend\n"
+	if diff := pretty.Compare(b.String(), want); diff != "" {
+		t.Error(diff)
+	}
+	if err = uuutil.ConvertReaders(b, true, "\n"); err == nil {
+		t.Error("Expected error but return nil")
+	}
+}
+
+func TestConvertFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"images/b.png": &fstest.MapFile{Data: []byte("abcdef"), Mode: 0600},
+		"images/a.png": &fstest.MapFile{Data: []byte("abc"), Mode: 0644},
+		"images/c.txt": &fstest.MapFile{Data: []byte("abc"), Mode: 0644},
+		"readme":       &fstest.MapFile{Data: []byte("abc"), Mode: 0755},
+	}
+	b := new(bytes.Buffer)
+	err := uuutil.ConvertFS(b, true, "\n", fsys, "images/*.png", "readme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "begin 644 a.png\n#86)C\n`\nend\nbegin 600 b.png\n&86)C9&5F\n`\n" +
+		"end\nbegin 755 readme\n#86)C\n`\nend\n"
+	if diff := pretty.Compare(b.String(), want); diff != "" {
+		t.Error(diff)
+	}
+	for _, patterns := range [][]string{nil, {"*.jpg"}, {"["}} {
+		if err = uuutil.ConvertFS(b, true, "\n", fsys,
+			patterns...); err == nil {
+			t.Errorf("%q Expected error but return nil", patterns)
+		}
+	}
+}
+
+func TestConvertParallel(t *testing.T) {
+	p := filepath.Join(tstFolder, tConvert, testConvertFiles[0])
+	files := []string{p + "_1.in", p + "_2.in", p + "_1.in", p + "_2.in",
+		p + "_1.in"}
+	want := new(bytes.Buffer)
+	if err := uuutil.Convert(want, true, "\n", files...); err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{0, 1, 2, 8} {
+		b := new(bytes.Buffer)
+		err := uuutil.ConvertParallel(b, true, "\n", workers, files...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := pretty.Compare(b.String(), want.String()); diff != "" {
+			t.Errorf("%d workers: %s", workers, diff)
+		}
+	}
+	b := new(bytes.Buffer)
+	err := uuutil.ConvertParallel(b, true, "\n", 2, files[0], "unknown file",
+		files[1])
+	if err == nil || !strings.HasPrefix(b.String(), "begin ") ||
+		strings.Count(b.String(), "begin ") != 1 {
+		t.Errorf("Got: %v %q", err, b)
+	}
+	if err = uuutil.ConvertParallel(b, true, "\n", 2); err == nil {
+		t.Error("Expected error but return nil")
+	}
+}
+
+// bufCloser is bytes.Buffer that records Close.
+type bufCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestConvertSplit(t *testing.T) {
+	p := filepath.Join(tstFolder, tConvert, testConvertFiles[0])
+	files := []string{p + "_1.in", p + "_2.in"}
+	msgs := make(map[string][]*bufCloser)
+	next := func(name string, i, n int) (io.WriteCloser, error) {
+		if i != len(msgs[name])+1 {
+			t.Fatalf("%s Got index %d of %d", name, i, n)
+		}
+		b := new(bufCloser)
+		msgs[name] = append(msgs[name], b)
+		return b, nil
+	}
+	err := uuutil.ConvertSplit(next, true, "\n", 16000, files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != len(files) {
+		t.Fatal("Got files: ", len(msgs))
+	}
+	for _, f := range files {
+		name := filepath.Base(f)
+		want, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := uu.NewAssembler(len(msgs[name]))
+		for i, b := range msgs[name] {
+			if b.Len() > 16000 || !b.closed || !strings.HasPrefix(b.String(),
+				"section ") {
+				t.Errorf("%s %d Got: %q", name, i, b)
+			}
+			if err = a.Add(i+1, b.Bytes()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		part, err := a.Part()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(part)
+		if err != nil || !bytes.Equal(got, want) || part.Header.Name != name {
+			t.Errorf("%s Got: %q %v", name, got, err)
+		}
+	}
+	if err := uuutil.ConvertSplit(next, true, "\n", 10, files...); err == nil {
+		t.Error("Expected error but return nil")
+	}
+}
+
+func TestConvertEach(t *testing.T) {
+	p := filepath.Join(tstFolder, tConvert, testConvertFiles[0])
+	files := []string{p + "_1.in", p + "_2.in"}
+	outs := make(map[string]*bufCloser)
+	create := func(name string) (io.WriteCloser, error) {
+		if name == "refused" {
+			return nil, errors.New("refused")
+		}
+		b := new(bufCloser)
+		outs[name] = b
+		return b, nil
+	}
+	if err := uuutil.ConvertEach(create, true, "\n", files...); err != nil {
+		t.Fatal(err)
+	}
+	if len(outs) != len(files) {
+		t.Fatal("Got files: ", len(outs))
+	}
+	for _, f := range files {
+		want := new(bytes.Buffer)
+		if err := uuutil.Convert(want, true, "\n", f); err != nil {
+			t.Fatal(err)
+		}
+		b := outs[filepath.Base(f)]
+		if b == nil || !b.closed || b.String() != want.String() {
+			t.Errorf("%s Got: %v", f, b)
+		}
+	}
+	for _, files := range [][]string{nil, {"unknown file"}, {"refused"}} {
+		if err := uuutil.ConvertEach(create, true, "\n",
+			files...); err == nil {
+			t.Errorf("%q Expected error but return nil", files)
+		}
+	}
+}
+
+// blockWriter blocks every Write until unblock is closed.
+type blockWriter struct {
+	unblock chan struct{}
+	mu      sync.Mutex
+	n       int
+}
+
+func (w *blockWriter) Write(b []byte) (int, error) {
+	<-w.unblock
+	w.mu.Lock()
+	w.n++
+	w.mu.Unlock()
+	return len(b), nil
+}
+
+// count returns the number of Write calls returned.
+func (w *blockWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.n
+}
+
+func TestConvertContext(t *testing.T) {
+	p := filepath.Join(tstFolder, tConvert, testConvertFiles[0])
+	files := []string{p + "_1.in", p + "_2.in"}
+	want := new(bytes.Buffer)
+	if err := uuutil.Convert(want, true, "\n", files...); err != nil {
+		t.Fatal(err)
+	}
+	b := new(bytes.Buffer)
+	err := uuutil.ConvertContext(context.TODO(), b, true, "\n", files...)
+	if err != nil || b.String() != want.String() {
+		t.Error("Got: ", err)
+	}
+	// canceled before start.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.Reset()
+	err = uuutil.ConvertContext(ctx, b, true, "\n", files...)
+	if err != context.Canceled || b.Len() != 0 {
+		t.Error("Got: ", err, b.Len())
+	}
+	// canceled while writer blocks, it returns after the blocked Write.
+	ctx, cancel = context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+	w := &blockWriter{unblock: make(chan struct{})}
+	go func() {
+		<-ctx.Done()
+		close(w.unblock)
+	}()
+	err = uuutil.ConvertContext(ctx, w, true, "\n", files...)
+	if err != context.DeadlineExceeded {
+		t.Error("Got: ", err)
+	}
+	n := w.count()
+	time.Sleep(20 * time.Millisecond)
+	if w.count() != n {
+		t.Error("Got writes after return")
+	}
+}
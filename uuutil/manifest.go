@@ -0,0 +1,51 @@
+package uuutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Manifest is the JSON document written by WithManifest.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is one uuencoded content of Manifest, see ExtractedFile.
+type ManifestFile struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	Size int64  `json:"size"`
+	// Mode is the octal permission bits of the begin line, eg: "0644".
+	Mode   string `json:"mode"`
+	Offset int64  `json:"offset"`
+	SHA256 string `json:"sha256,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WithManifest sets w that Parse writes the JSON Manifest of the reported
+// files into after the extraction, eg: for downstream pipelines to audit what
+// an extraction produced. The failed files are included with the error. The
+// error of writing w is returned by Parse if the extraction succeeds.
+func WithManifest(w io.Writer) ParseOption {
+	return func(c *parseConfig) {
+		c.manifest = w
+	}
+}
+
+// writeManifest writes the Manifest of files into w.
+func writeManifest(w io.Writer, files []ExtractedFile) error {
+	m := Manifest{Files: make([]ManifestFile, 0, len(files))}
+	for _, f := range files {
+		mf := ManifestFile{Name: f.Name, Path: f.Path, Size: f.Size,
+			Mode: fmt.Sprintf("%04o", f.Mode.Perm()), Offset: f.Offset,
+			SHA256: f.SHA256}
+		if f.Err != nil {
+			mf.Error = f.Err.Error()
+		}
+		m.Files = append(m.Files, mf)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
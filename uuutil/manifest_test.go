@@ -0,0 +1,41 @@
+package uuutil_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode/uuutil"
+	"golang.org/x/net/context"
+)
+
+func TestParseManifest(t *testing.T) {
+	in := "text\nbegin 644 a.txt\n#86)C\n`\nend\nbegin 600 ..\n#9&5F\n`\nend\n"
+	var b bytes.Buffer
+	_, files, err := uuutil.ParseToMap(context.TODO(),
+		bytes.NewBufferString(in), uuutil.WithManifest(&b))
+	if err != nil || len(files) != 2 {
+		t.Fatal("Got: ", files, err)
+	}
+	var m uuutil.Manifest
+	if err = json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	want := uuutil.Manifest{Files: []uuutil.ManifestFile{
+		{Name: "a.txt", Path: "a.txt", Size: 3, Mode: "0644", Offset: 5,
+			SHA256: "ba7816bf8f01cfea414140de5dae2223" +
+				"b00361a396177a9cb410ff61f20015ad"},
+		{Name: "..", Mode: "0600", Offset: 33,
+			Error: uuutil.ErrUnsafeName.Error()},
+	}}
+	if diff := pretty.Compare(m, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+	// the checksum is only computed for the manifest.
+	_, files, err = uuutil.ParseToMap(context.TODO(),
+		bytes.NewBufferString(in))
+	if err != nil || files[0].SHA256 != "" || files[0].Offset != 5 {
+		t.Error("Got: ", files, err)
+	}
+}
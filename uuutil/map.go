@@ -0,0 +1,57 @@
+package uuutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/net/context"
+)
+
+// mapFS is FS that keeps files in memory.
+type mapFS struct {
+	m map[string][]byte
+	// unnamed is the number of contents without file name.
+	unnamed int
+}
+
+// mapFile stores its content into the map on Close.
+type mapFile struct {
+	bytes.Buffer
+	fs   *mapFS
+	name string
+}
+
+func (f *mapFile) Close() error {
+	f.fs.m[f.name] = f.Bytes()
+	return nil
+}
+
+func (fs *mapFS) CreateFile(name string) (io.WriteCloser, error) {
+	w, _, err := fs.createFileMode(name, 0)
+	return w, err
+}
+
+// createFileMode returns the key of the file, the permission bits are not
+// kept.
+func (fs *mapFS) createFileMode(name string, _ os.FileMode) (io.WriteCloser,
+	string, error) {
+	if name == "" {
+		fs.unnamed++
+		name = fmt.Sprintf("uu_%d", fs.unnamed)
+	}
+	return &mapFile{fs: fs, name: name}, name, nil
+}
+
+// ParseToMap is like Parse but returns the decoded contents in memory keyed by
+// file name, for small input. Content without file name is keyed as uu_1, uu_2
+// and so on, and later content replaces the earlier one of the same name. The
+// bytes outside uuencoded contents are dropped.
+func ParseToMap(ctx context.Context, r io.Reader, opts ...ParseOption) (
+	map[string][]byte, []ExtractedFile, error) {
+	fs := &mapFS{m: make(map[string][]byte)}
+	opts = append(opts, WithFS(fs))
+	files, err := Parse(ctx, nil, "", r, opts...)
+	return fs.m, files, err
+}
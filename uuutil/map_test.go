@@ -0,0 +1,29 @@
+package uuutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode/uuutil"
+	"golang.org/x/net/context"
+)
+
+func TestParseToMap(t *testing.T) {
+	in := "junk\nbegin 600 a.txt\n#86)C\n`\nend\nbegin 644 ../b.txt\n" +
+		"&86)C9&5F\n`\nend\nbegin 644\n#86)C\n`\nend\n" +
+		"begin 644 a.txt\n#9&5F\n`\nend\n"
+	m, files, err := uuutil.ParseToMap(context.TODO(),
+		bytes.NewBufferString(in))
+	if err != nil || len(files) != 4 || files[2].Path != "uu_1" {
+		t.Fatal("Got: ", files, err)
+	}
+	want := map[string][]byte{
+		"a.txt": []byte("def"),
+		"b.txt": []byte("abcdef"),
+		"uu_1":  []byte("abc"),
+	}
+	if diff := pretty.Compare(m, want); diff != "" {
+		t.Error(diff)
+	}
+}
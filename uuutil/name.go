@@ -0,0 +1,76 @@
+package uuutil
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// WithPercentNames sets whether the %XX escapes of the begin line file names
+// are decoded, eg: "my%20file.txt" is extracted as "my file.txt". Invalid
+// escapes are kept as is. The decoded name is checked like the other names,
+// see WithUnsafeNames.
+func WithPercentNames(decode bool) ParseOption {
+	return func(c *parseConfig) {
+		c.percentNames = decode
+	}
+}
+
+// WithNameCharset sets the character set of the begin line file names that are
+// not valid UTF-8, eg: charmap.ISO8859_1 for Latin-1 names. Such names are
+// converted into UTF-8 and the bytes that still can not be converted are
+// replaced by '_'. Valid UTF-8 names are used as is. Nil, the default, uses
+// every name as is.
+func WithNameCharset(cs encoding.Encoding) ParseOption {
+	return func(c *parseConfig) {
+		c.nameCharset = cs
+	}
+}
+
+// fileName returns the begin line file name decoded as set by
+// WithPercentNames and WithNameCharset.
+func (c *parseConfig) fileName(name string) string {
+	if c.percentNames {
+		name = percentDecode(name)
+	}
+	if c.nameCharset == nil || utf8.ValidString(name) {
+		return name
+	}
+	if s, err := c.nameCharset.NewDecoder().String(name); err == nil {
+		name = s
+	}
+	return strings.ToValidUTF8(name, "_")
+}
+
+// percentDecode returns s with the valid %XX escapes decoded.
+func percentDecode(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if h, l := unhex(s[i+1]), unhex(s[i+2]); h < 16 && l < 16 {
+				b = append(b, h<<4|l)
+				i += 2
+				continue
+			}
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+// unhex returns the value of hex digit c, or 16 if c is not hex digit.
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10
+	}
+	return 16
+}
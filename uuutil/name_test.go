@@ -0,0 +1,56 @@
+package uuutil_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode/uuutil"
+	"golang.org/x/net/context"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestParseNames(t *testing.T) {
+	const body = "\n#86)C\n`\nend\n"
+	tsts := []struct {
+		name string
+		opts []uuutil.ParseOption
+		want string
+		err  error
+	}{
+		{"my%20file.txt", nil, "my%20file.txt", nil},
+		{"my%20file.txt", []uuutil.ParseOption{uuutil.WithPercentNames(true)},
+			"my file.txt", nil},
+		{"100%.txt%2", []uuutil.ParseOption{uuutil.WithPercentNames(true)},
+			"100%.txt%2", nil},
+		{"caf\xe9.txt", []uuutil.ParseOption{
+			uuutil.WithNameCharset(charmap.ISO8859_1)}, "café.txt", nil},
+		{"café.txt", []uuutil.ParseOption{
+			uuutil.WithNameCharset(charmap.ISO8859_1)}, "café.txt", nil},
+		{"caf%E9.txt", []uuutil.ParseOption{uuutil.WithPercentNames(true),
+			uuutil.WithNameCharset(charmap.ISO8859_1)}, "café.txt", nil},
+		{"price\x80.txt", []uuutil.ParseOption{
+			uuutil.WithNameCharset(charmap.Windows1252)}, "price€.txt", nil},
+		// the decoded name is still checked.
+		{"..%2F..%2Fx", []uuutil.ParseOption{uuutil.WithPercentNames(true)},
+			"x", nil},
+		{"%2E%2E", []uuutil.ParseOption{uuutil.WithPercentNames(true)}, "",
+			uuutil.ErrUnsafeName},
+	}
+	for i, tst := range tsts {
+		m, files, err := uuutil.ParseToMap(context.TODO(),
+			bytes.NewBufferString("begin 644 "+tst.name+body), tst.opts...)
+		if err != nil || len(files) != 1 {
+			t.Fatal(i, "Got: ", files, err)
+		}
+		if !errors.Is(files[0].Err, tst.err) || files[0].Path != tst.want {
+			t.Errorf("%d Got: %+v", i, files[0])
+		}
+		if tst.err == nil {
+			if diff := pretty.Compare(string(m[tst.want]), "abc"); diff != "" {
+				t.Errorf("%d Diff: %s", i, diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package uuutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// tarFS is FS that writes files as the entries of tar stream.
+type tarFS struct {
+	tw *tar.Writer
+	// unnamed is the number of contents without file name.
+	unnamed int
+}
+
+// tarFile buffers the content until Close as tar header needs the size. The
+// failed content is dropped by abort.
+type tarFile struct {
+	bytes.Buffer
+	tw  *tar.Writer
+	hdr tar.Header
+}
+
+func (f *tarFile) Close() error {
+	f.hdr.Size = int64(f.Len())
+	if err := f.tw.WriteHeader(&f.hdr); err != nil {
+		return err
+	}
+	_, err := f.tw.Write(f.Bytes())
+	return err
+}
+
+func (f *tarFile) abort() {
+	f.Reset()
+}
+
+func (t *tarFS) CreateFile(name string) (io.WriteCloser, error) {
+	w, _, err := t.createFileMode(name, 0644)
+	return w, err
+}
+
+func (t *tarFS) createFileMode(name string, mode os.FileMode) (io.WriteCloser,
+	string, error) {
+	if name == "" {
+		t.unnamed++
+		name = fmt.Sprintf("uu_%d", t.unnamed)
+	}
+	f := &tarFile{tw: t.tw, hdr: tar.Header{
+		Name:     name,
+		Mode:     int64(mode.Perm()) | tarModeBits(mode),
+		Typeflag: tar.TypeReg,
+		ModTime:  time.Now(),
+	}}
+	return f, name, nil
+}
+
+// tarModeBits returns the setuid, setgid and sticky bits of mode in tar
+// format.
+func tarModeBits(mode os.FileMode) int64 {
+	var m int64
+	if mode&os.ModeSetuid != 0 {
+		m |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= 01000
+	}
+	return m
+}
+
+// ParseToTar is like ParseToZip but writes every decoded content as the entry
+// of tw. Each content is buffered in memory until it ends as the size is
+// needed before the content, so use WithMaxFileSize for untrusted input. The
+// content that fails decoding or exceeds WithMaxFileSize is not written into
+// tw. tw is not closed.
+func ParseToTar(ctx context.Context, tw *tar.Writer, r io.Reader,
+	opts ...ParseOption) ([]ExtractedFile, error) {
+	opts = append(opts, WithFS(&tarFS{tw: tw}))
+	return Parse(ctx, nil, "", r, opts...)
+}
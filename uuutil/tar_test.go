@@ -0,0 +1,88 @@
+package uuutil_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sanylcs/uuencode/uuutil"
+	"golang.org/x/net/context"
+)
+
+func TestParseToTar(t *testing.T) {
+	in := "junk\nbegin 600 a.txt\n#86)C\n`\nend\nbegin 4755 ../b.txt\n" +
+		"&86)C9&5F\n`\nend\nbegin 644\n#86)C\n`\nend\n"
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files, err := uuutil.ParseToTar(context.TODO(), tw,
+		bytes.NewBufferString(in))
+	if err != nil || len(files) != 3 || files[2].Path != "uu_1" {
+		t.Fatal("Got: ", files, err)
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name    string
+		mode    int64
+		content string
+	}{
+		{"a.txt", 0600, "abc"},
+		{"b.txt", 0755, "abcdef"},
+		{"uu_1", 0644, "abc"},
+	}
+	tr := tar.NewReader(&buf)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			if i != len(want) {
+				t.Error("Got entries: ", i)
+			}
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		} else if i >= len(want) {
+			t.Fatal("Too many entries: ", hdr.Name)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil || hdr.Name != want[i].name || hdr.Mode != want[i].mode ||
+			hdr.Size != int64(len(want[i].content)) ||
+			string(b) != want[i].content {
+			t.Errorf("Got: %s %o %d %q %v", hdr.Name, hdr.Mode, hdr.Size, b,
+				err)
+		}
+	}
+}
+
+func TestParseToTarFailed(t *testing.T) {
+	in := "begin 644 a.txt\n#86)C\n`\nend\nbegin 644 big.txt\n$86)C9```\n`\n" +
+		"end\nbegin 644 bad.txt\n#86)C\n#86)\n`\nend\n"
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files, _ := uuutil.ParseToTar(context.TODO(), tw,
+		bytes.NewBufferString(in), uuutil.WithMaxFileSize(3))
+	if len(files) != 3 || files[1].Err == nil || files[2].Err == nil ||
+		files[1].Path != "" || files[2].Path != "" {
+		t.Fatal("Got: ", files)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// only the good content is in the archive.
+	var names []string
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if len(names) != 1 || names[0] != "a.txt" {
+		t.Error("Got entries: ", names)
+	}
+}
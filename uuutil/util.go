@@ -1,16 +1,22 @@
 package uuutil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
-	"strconv"
+	"strings"
 	"sync"
 
 	uu "github.com/sanylcs/uuencode"
 	"golang.org/x/net/context"
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/transform"
 )
 
@@ -18,29 +24,11 @@ import (
 // mean grave character is used for zero bit. eol is end of line characters.
 func Convert(w io.Writer, useGrave bool, eol string, files ...string) error {
 	if len(files) <= 0 {
-		return errors.New("nothing to convert")
+		return errNothing
 	}
-	e := uu.NewEncode(useGrave, eol)
 	// loop through all the input files
 	for _, f := range files {
-		rc, err := os.Open(f)
-		if err != nil {
-			return err
-		}
-		fi, err := rc.Stat()
-		if err != nil {
-			return err
-		}
-		// format int to string file permission should be in base-8.
-		permit := strconv.FormatUint(uint64(fi.Mode().Perm()), 8)
-		e.ResetAll(permit, fi.Name())
-		// write the converted result into w which is provided by caller.
-		_, err = io.Copy(w, transform.NewReader(rc, e))
-		if err != nil {
-			return err
-		}
-		// close and release the file contents.
-		if err = rc.Close(); err != nil {
+		if err := encodeFile(w, useGrave, eol, f); err != nil {
 			return err
 		}
 	}
@@ -61,54 +49,310 @@ func getDir(once *sync.Once, dir string) (string, error) {
 	return dir, err
 }
 
+// ExtractedFile reports the extraction of one uuencoded content by Parse.
+type ExtractedFile struct {
+	// Name and Mode are the file name and permission bits of the begin line.
+	Name string
+	Mode os.FileMode
+	// Path is the written file path. It is empty if the file is not created.
+	Path string
+	// Size is the number of decoded bytes written.
+	Size int64
+	// Offset is the byte offset of the begin line in the input.
+	Offset int64
+	// SHA256 is the hex SHA-256 of the written bytes. It is only set with
+	// WithManifest.
+	SHA256 string
+	// Collision is the policy applied because the file exists, or
+	// CollisionNone.
+	Collision CollisionPolicy
+	// Err is the failure of extracting this file.
+	Err error
+}
+
+// ErrUnsafeName indicates the begin line file name escapes the target
+// directory, eg: "../../.ssh/authorized_keys".
+var ErrUnsafeName = errors.New("uuutil: unsafe file name")
+
+// ErrQuota indicates the extraction exceeds the size limit set by
+// WithMaxFileSize or WithMaxTotalSize.
+var ErrQuota = errors.New("uuutil: extraction quota exceeded")
+
+// QuotaError indicates the extraction of file Name exceeds the size limit. It
+// wraps ErrQuota.
+type QuotaError struct {
+	// Name is the file name of the begin line.
+	Name string
+	// Limit is the exceeded limit in bytes.
+	Limit int64
+	// Total is true if the limit is of all extracted files.
+	Total bool
+}
+
+func (e *QuotaError) Error() string {
+	kind := "file"
+	if e.Total {
+		kind = "total"
+	}
+	return fmt.Sprintf("uuutil: %q exceeds %s size limit of %d bytes", e.Name,
+		kind, e.Limit)
+}
+
+// Unwrap returns ErrQuota.
+func (e *QuotaError) Unwrap() error {
+	return ErrQuota
+}
+
+// CollisionPolicy decides what Parse does when the file to extract exists,
+// eg: two uuencoded contents share the same file name.
+type CollisionPolicy int
+
+const (
+	// CollisionNone reports there is no collision. As policy, it is the same
+	// as CollisionOverwrite.
+	CollisionNone CollisionPolicy = iota
+	// CollisionOverwrite truncates and overwrites the existing file.
+	CollisionOverwrite
+	// CollisionError fails the extraction of the file with error satisfying
+	// os.IsExist.
+	CollisionError
+	// CollisionSkip skips the extraction of the file without error.
+	CollisionSkip
+	// CollisionRename extracts the file with a new name, eg: file(1).txt.
+	CollisionRename
+)
+
+// parseConfig holds the settings of Parse.
+type parseConfig struct {
+	unsafeNames bool
+	stripDirs   bool
+	collision   CollisionPolicy
+	applyMode   bool
+	modeMask    os.FileMode
+	maxFile     int64
+	maxTotal    int64
+	fs          FS
+	filter      func(uu.Header) bool
+	progress    func(ParseProgress)
+	manifest    io.Writer
+	// percentNames and nameCharset decode the begin line file names.
+	percentNames bool
+	nameCharset  encoding.Encoding
+	// written is the total bytes extracted so far.
+	written int64
+}
+
+// ParseOption configures Parse.
+type ParseOption func(*parseConfig)
+
+// WithUnsafeNames sets whether the begin line file names are used as is. By
+// default, the directory part of the name is stripped and name that still
+// can not be a file inside the target directory, eg: "..", is rejected with
+// ErrUnsafeName. Only allow it for trusted input.
+func WithUnsafeNames(allow bool) ParseOption {
+	return func(c *parseConfig) {
+		c.unsafeNames = allow
+	}
+}
+
+// WithStripDirs sets whether the directory part of the begin line file names,
+// both / and \ separated, is stripped even if WithUnsafeNames allows the names
+// as is, eg: "some/dir/file.bin" is extracted as "file.bin" in the target
+// directory like the classic uudecode. The stripped name is otherwise used as
+// is.
+func WithStripDirs(strip bool) ParseOption {
+	return func(c *parseConfig) {
+		c.stripDirs = strip
+	}
+}
+
+// WithCollision sets the policy when the file to extract exists. The default is
+// CollisionOverwrite.
+func WithCollision(policy CollisionPolicy) ParseOption {
+	return func(c *parseConfig) {
+		c.collision = policy
+	}
+}
+
+// FS is the file system that Parse extracts into instead of the directory, eg:
+// memory, object store or test doubles.
+type FS interface {
+	// CreateFile creates the file name for writing the decoded content. name
+	// is empty if the begin line has no file name.
+	CreateFile(name string) (io.WriteCloser, error)
+}
+
+// modeFS is FS that also stores the permission bits of the begin line. It
+// returns the name of the created file.
+type modeFS interface {
+	FS
+	createFileMode(name string, mode os.FileMode) (io.WriteCloser, string,
+		error)
+}
+
+// aborter is the file of FS that drops the written content instead of storing
+// it on failure, eg: archive entries.
+type aborter interface {
+	abort()
+}
+
+// WithFS sets the file system that Parse extracts into. The dir argument of
+// Parse is ignored. The extracted file is reported with its name as Path. The
+// collision policy, permission bits and removal of file exceeding the quota
+// are left to fs.
+func WithFS(fs FS) ParseOption {
+	return func(c *parseConfig) {
+		c.fs = fs
+	}
+}
+
+// WithFilter sets the function that decides whether the content of the begin
+// line h is extracted, eg: only *.jpg files. The content is skipped without
+// writing if it returns false. The skipped contents are not reported.
+func WithFilter(keep func(h uu.Header) bool) ParseOption {
+	return func(c *parseConfig) {
+		c.filter = keep
+	}
+}
+
+// ParseProgress reports the progress of Parse.
+type ParseProgress struct {
+	// Name is the file name of the uuencoded content being decoded.
+	Name string
+	// In is the total bytes read from input and Out is the total decoded
+	// bytes.
+	In, Out int64
+	// Parts is the number of uuencoded contents completely extracted,
+	// including the failed and skipped ones.
+	Parts int
+}
+
+// WithProgress sets f to be called as the decoding advances and after each
+// uuencoded content is extracted. f is never called concurrently and should
+// return quickly.
+func WithProgress(f func(ParseProgress)) ParseOption {
+	return func(c *parseConfig) {
+		c.progress = f
+	}
+}
+
+// WithMaxFileSize limits the bytes written of each extracted file to n. The
+// file exceeding it is removed and reported with *QuotaError. Zero means no
+// limit.
+func WithMaxFileSize(n int64) ParseOption {
+	return func(c *parseConfig) {
+		c.maxFile = n
+	}
+}
+
+// WithMaxTotalSize limits the bytes written of all extracted files to n. The
+// file exceeding it is removed and Parse stops with *QuotaError. Zero means no
+// limit.
+func WithMaxTotalSize(n int64) ParseOption {
+	return func(c *parseConfig) {
+		c.maxTotal = n
+	}
+}
+
+// WithApplyMode sets whether the permission bits of the begin line are applied
+// to the extracted files instead of 0644. The bits are masked by the mask set
+// by WithModeMask.
+func WithApplyMode(apply bool) ParseOption {
+	return func(c *parseConfig) {
+		c.applyMode = apply
+	}
+}
+
+// WithModeMask sets the mask of the permission bits applied by WithApplyMode.
+// The default is os.ModePerm which strips the setuid, setgid and sticky bits.
+func WithModeMask(mask os.FileMode) ParseOption {
+	return func(c *parseConfig) {
+		c.modeMask = mask
+	}
+}
+
+// baseName returns the last element of name which is both / and \ separated.
+func baseName(name string) string {
+	return path.Base(strings.Replace(name, "\\", "/", -1))
+}
+
+// safeName returns the base name of the begin line file name which is both /
+// and \ separated, or ErrUnsafeName.
+func safeName(name string) (string, error) {
+	name = baseName(name)
+	if name == "." || name == ".." || name == "/" ||
+		strings.ContainsRune(name, 0) || filepath.VolumeName(name) != "" {
+		return "", ErrUnsafeName
+	}
+	return name, nil
+}
+
 // Parse decode uuencoded data from r into directory path dir and write any non
-// uuencode bytes into w. Parse block decoding finish or error.
-func Parse(ctx context.Context, w io.Writer, dir string, r io.Reader) error {
+// uuencode bytes into w. Parse block decoding finish or error. It returns the
+// report of every uuencoded content found, including the failed ones.
+func Parse(ctx context.Context, w io.Writer, dir string, r io.Reader,
+	opts ...ParseOption) ([]ExtractedFile, error) {
+	cfg := parseConfig{modeMask: os.ModePerm}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	var wait sync.WaitGroup
 	if w == nil {
 		w = ioutil.Discard
 	}
 	wait.Add(2)
-	d, cancel, ch := uu.NewMultiDecode()
+	d, cancel, ch := uu.NewMultiDecodeParts()
+	// cancel may be called by either goroutine below but can only run once.
+	var cancelOnce sync.Once
+	stop := func() { cancelOnce.Do(cancel) }
+	var files []ExtractedFile
+	var quotaErr error
+	// progress is shared by the decoding and extracting goroutines.
+	var progress struct {
+		sync.Mutex
+		ParseProgress
+	}
+	report := func(update func(*ParseProgress)) {
+		if cfg.progress == nil {
+			return
+		}
+		progress.Lock()
+		update(&progress.ParseProgress)
+		cfg.progress(progress.ParseProgress)
+		progress.Unlock()
+	}
+	if cfg.progress != nil {
+		d.OnProgress(func(p uu.Progress) {
+			report(func(pp *ParseProgress) {
+				pp.Name, pp.In, pp.Out = d.Header().Name, p.In, p.Out
+			})
+		})
+	}
 	// run reading of decoded result in goroutine
 	go func() {
-		var (
-			once sync.Once
-			err  error
-		)
+		var once sync.Once
 		defer wait.Done()
-		// get the io.Reader from chan
-		for r := range ch {
-			dir, err = getDir(&once, dir)
-			if err != nil {
-				r.Close()
+		// get the decoded content from chan
+		for p := range ch {
+			if quotaErr != nil || cfg.filter != nil && !cfg.filter(p.Header) {
+				// drop the content that is found before stopping or not
+				// wanted.
+				p.Close()
+				report(func(pp *ParseProgress) { pp.Parts++ })
 				continue
 			}
-			// create the filenames either base on the input file's begin header
-			// or create random file is filename can not be found on the begin
-			// header.
-			var f *os.File
-			if d.Filename != "" {
-				name := filepath.Join(dir, d.Filename)
-				// create or overwrite the content of existing file.
-				f, err = os.OpenFile(name,
-					os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-			} else {
-				// create a random file inside the provided directory.
-				f, err = ioutil.TempFile(dir, "uu_")
-			}
-			if err != nil {
-				r.Close()
-				continue
+			ef := ExtractedFile{Name: p.Header.Name, Mode: p.Header.Mode,
+				Offset: p.Offset}
+			extract(&once, dir, p, &cfg, &ef)
+			// unblock the decoding of the content not read.
+			p.Close()
+			files = append(files, ef)
+			report(func(pp *ParseProgress) { pp.Parts++ })
+			qe, ok := ef.Err.(*QuotaError)
+			if ok && qe.Total && quotaErr == nil {
+				quotaErr = qe
+				stop()
 			}
-			// copy out the content of decoded contents into file.
-			_, err = io.Copy(f, r)
-			if err != nil {
-				r.Close()
-				f.Close()
-				continue
-			}
-			f.Close()
 		}
 	}()
 	// decoding process run in goroutine as to allow cancelable action on
@@ -130,14 +374,169 @@ func Parse(ctx context.Context, w io.Writer, dir string, r io.Reader) error {
 	var err2 error
 	select {
 	case <-ctx.Done():
-		cancel()
+		stop()
 		err2 = ctx.Err()
 	case <-done:
 	}
 	// done signaling here both reading goroutine and process goroutine ended.
 	<-done
-	if err1 == nil {
+	if quotaErr != nil {
+		err1 = quotaErr
+	} else if err1 == nil {
 		err1 = err2
 	}
-	return err1
+	if cfg.manifest != nil {
+		if err := writeManifest(cfg.manifest, files); err1 == nil {
+			err1 = err
+		}
+	}
+	return files, err1
+}
+
+// extract writes the decoded content of p into dir and fills the result into
+// ef.
+func extract(once *sync.Once, dir string, p uu.MultiPart, cfg *parseConfig,
+	ef *ExtractedFile) {
+	name := cfg.fileName(p.Header.Name)
+	if name != "" && cfg.stripDirs {
+		name = baseName(name)
+	}
+	if name != "" && !cfg.unsafeNames {
+		if name, ef.Err = safeName(name); ef.Err != nil {
+			return
+		}
+	}
+	var f io.WriteCloser
+	if mfs, ok := cfg.fs.(modeFS); ok {
+		// Header.Mode is zero for invalid permission reported by p.Err.
+		mode := p.Header.Mode
+		if p.Err != nil {
+			mode = 0644
+		}
+		f, ef.Path, ef.Err = mfs.createFileMode(name, mode&cfg.modeMask)
+		if ef.Err != nil {
+			return
+		}
+	} else if cfg.fs != nil {
+		if f, ef.Err = cfg.fs.CreateFile(name); ef.Err != nil {
+			return
+		}
+		ef.Path = name
+	} else if f, ef.Path, ef.Collision, ef.Err = createInDir(once, dir, name,
+		cfg.collision); f == nil {
+		return
+	}
+	// copy out the content of decoded contents into file, one byte more than
+	// the limit to detect it is exceeded.
+	limit, qe := cfg.limit(p.Header.Name)
+	var r io.Reader = p
+	if qe != nil {
+		r = io.LimitReader(p, limit+1)
+	}
+	var w io.Writer = f
+	var sum hash.Hash
+	if cfg.manifest != nil {
+		sum = sha256.New()
+		w = io.MultiWriter(f, sum)
+	}
+	ef.Size, ef.Err = io.Copy(w, r)
+	exceeded := qe != nil && ef.Size > limit
+	if exceeded {
+		ef.Err = qe
+	}
+	if a, ok := f.(aborter); ok && ef.Err != nil {
+		a.abort()
+		ef.Path = ""
+	} else if err := f.Close(); ef.Err == nil {
+		ef.Err = err
+	}
+	if exceeded {
+		if cfg.fs == nil {
+			os.Remove(ef.Path)
+			ef.Path = ""
+		}
+		ef.Size = 0
+		return
+	}
+	if sum != nil {
+		ef.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	}
+	cfg.written += ef.Size
+	if p.Err == nil && cfg.applyMode && cfg.fs == nil && ef.Err == nil {
+		ef.Err = os.Chmod(ef.Path, p.Header.Mode&cfg.modeMask)
+	}
+}
+
+// createInDir creates the file name inside dir, or random file if name is
+// empty. It returns nil io.WriteCloser if the file is not created.
+func createInDir(once *sync.Once, dir, name string, policy CollisionPolicy) (
+	io.WriteCloser, string, CollisionPolicy, error) {
+	dir, err := getDir(once, dir)
+	if err != nil {
+		return nil, "", CollisionNone, err
+	}
+	// create the filenames either base on the input file's begin header or
+	// create random file is filename can not be found on the begin header.
+	var f *os.File
+	collision := CollisionNone
+	if name != "" {
+		f, collision, err = create(filepath.Join(dir, name), policy)
+	} else {
+		// create a random file inside the provided directory.
+		f, err = ioutil.TempFile(dir, "uu_")
+	}
+	if f == nil {
+		return nil, "", collision, err
+	}
+	return f, f.Name(), collision, err
+}
+
+// limit returns the bytes allowed for the next file and the error reported if
+// it is exceeded. The error is nil if there is no limit.
+func (c *parseConfig) limit(name string) (int64, *QuotaError) {
+	var limit int64
+	var qe *QuotaError
+	if c.maxFile > 0 {
+		limit, qe = c.maxFile, &QuotaError{Name: name, Limit: c.maxFile}
+	}
+	if left := c.maxTotal - c.written; c.maxTotal > 0 &&
+		(qe == nil || left < limit) {
+		if left < 0 {
+			left = 0
+		}
+		limit = left
+		qe = &QuotaError{Name: name, Limit: c.maxTotal, Total: true}
+	}
+	return limit, qe
+}
+
+// create creates the file name applying policy if it exists. It returns the
+// applied policy or CollisionNone if there is no collision. The returned file
+// is nil if it is not created.
+func create(name string, policy CollisionPolicy) (*os.File, CollisionPolicy,
+	error) {
+	flag := os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	f, err := os.OpenFile(name, flag, 0644)
+	if err == nil || !os.IsExist(err) {
+		return f, CollisionNone, err
+	}
+	switch policy {
+	case CollisionError:
+		return nil, policy, err
+	case CollisionSkip:
+		return nil, policy, nil
+	case CollisionRename:
+		ext := filepath.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		for i := 1; ; i++ {
+			f, err = os.OpenFile(fmt.Sprintf("%s(%d)%s", base, i, ext), flag,
+				0644)
+			if err == nil || !os.IsExist(err) {
+				return f, policy, err
+			}
+		}
+	}
+	// create or overwrite the content of existing file.
+	f, err = os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	return f, CollisionOverwrite, err
 }
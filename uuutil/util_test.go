@@ -2,6 +2,7 @@ package uuutil_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/kylelemons/godebug/pretty"
+	uu "github.com/sanylcs/uuencode"
 	"github.com/sanylcs/uuencode/uuutil"
 	"golang.org/x/net/context"
 )
@@ -119,11 +121,25 @@ func TestParse(t *testing.T) {
 	defer os.RemoveAll(dirTemp)
 	for _, f := range testParseFiles {
 		rc := readInputFile(tstParse, f)
-		err := uuutil.Parse(context.TODO(), nil, dirTemp, rc)
+		files, err := uuutil.Parse(context.TODO(), nil, dirTemp, rc)
 		if err != nil {
 			t.Error("Expected nil-error but got:", err)
 		}
 		rc.Close()
+		if len(files) != 2 {
+			t.Fatal("Expected 2 extracted files but got:", files)
+		}
+		for i, ef := range files {
+			name := fmt.Sprintf("pic%d.jpg", i+1)
+			if ef.Name != name || ef.Mode != 0666 || ef.Err != nil ||
+				ef.Path != filepath.Join(dirTemp, name) {
+				t.Errorf("Got: %+v", ef)
+			}
+			fi, err := os.Stat(ef.Path)
+			if err != nil || fi.Size() != ef.Size || ef.Size == 0 {
+				t.Error("Got size:", ef.Size, err)
+			}
+		}
 	}
 }
 
@@ -141,7 +157,7 @@ func TestParseUnknownDir(t *testing.T) {
 		dir := fmt.Sprint(unknownDir, i)
 		func() {
 			defer os.RemoveAll(dir)
-			err := uuutil.Parse(context.TODO(), nil, dir, rc)
+			_, err := uuutil.Parse(context.TODO(), nil, dir, rc)
 			if err != nil {
 				t.Error("Expected nil-error but got:", err)
 			}
@@ -154,8 +170,324 @@ func TestParseCancel(t *testing.T) {
 	rc := readInputFile(tstParse, testParseFiles[0])
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	err := uuutil.Parse(ctx, nil, dirTemp, rc)
+	_, err := uuutil.Parse(ctx, nil, dirTemp, rc)
+	if err == nil {
+		t.Error("Expected error but no error")
+	}
+}
+
+func TestParseReportFailure(t *testing.T) {
+	defer os.RemoveAll(dirTemp)
+	in := "begin 644 good.txt\n#86)C\n`\nend\n" +
+		"begin 644 bad.txt\n#86)C\n#86)\n`\nend\n"
+	files, err := uuutil.Parse(context.TODO(), nil, dirTemp,
+		bytes.NewBufferString(in))
 	if err == nil {
 		t.Error("Expected error but no error")
 	}
+	if len(files) != 2 {
+		t.Fatal("Expected 2 extracted files but got:", files)
+	}
+	if files[0].Err != nil || files[0].Size != 3 {
+		t.Errorf("Got: %+v", files[0])
+	}
+	if files[1].Name != "bad.txt" || files[1].Err == nil {
+		t.Errorf("Got: %+v", files[1])
+	}
+}
+
+func TestParseHostileNames(t *testing.T) {
+	defer os.RemoveAll(dirTemp)
+	tsts := []struct {
+		name, path string
+	}{
+		{"../../evil.txt", "evil.txt"},
+		{"/etc/evil2.txt", "evil2.txt"},
+		{`..\..\evil3.txt`, "evil3.txt"},
+		{"..", ""},
+		{"a/../..", ""},
+	}
+	var in string
+	for _, tst := range tsts {
+		in += "begin 644 " + tst.name + "\n#86)C\n`\nend\n"
+	}
+	files, err := uuutil.Parse(context.TODO(), nil, dirTemp,
+		bytes.NewBufferString(in))
+	if err != nil {
+		t.Fatal("Expected nil-error but got:", err)
+	}
+	if len(files) != len(tsts) {
+		t.Fatal("Got: ", files)
+	}
+	for i, tst := range tsts {
+		ef := files[i]
+		if tst.path == "" {
+			if ef.Err != uuutil.ErrUnsafeName || ef.Path != "" {
+				t.Errorf("Got: %+v", ef)
+			}
+			continue
+		}
+		if ef.Err != nil || ef.Path != filepath.Join(dirTemp, tst.path) {
+			t.Errorf("Got: %+v", ef)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tstFolder, "evil.txt")); err == nil {
+		os.Remove(filepath.Join(tstFolder, "evil.txt"))
+		t.Error("file escaped the target directory")
+	}
+}
+
+func TestParseUnsafeNames(t *testing.T) {
+	defer os.RemoveAll(dirTemp)
+	if err := os.MkdirAll(filepath.Join(dirTemp, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	in := "begin 644 sub/ok.txt\n#86)C\n`\nend\n"
+	files, err := uuutil.Parse(context.TODO(), nil, dirTemp,
+		bytes.NewBufferString(in), uuutil.WithUnsafeNames(true))
+	if err != nil || len(files) != 1 || files[0].Err != nil ||
+		files[0].Path != filepath.Join(dirTemp, "sub", "ok.txt") {
+		t.Errorf("Got: %+v %v", files, err)
+	}
+}
+
+func TestParseStripDirs(t *testing.T) {
+	defer os.RemoveAll(dirTemp)
+	in := "begin 644 some/dir/file.bin\n#86)C\n`\nend\n" +
+		"begin 644 other\\dir\\file2.bin\n#86)C\n`\nend\n"
+	files, err := uuutil.Parse(context.TODO(), nil, dirTemp,
+		bytes.NewBufferString(in), uuutil.WithUnsafeNames(true),
+		uuutil.WithStripDirs(true))
+	if err != nil || len(files) != 2 {
+		t.Fatal("Got: ", files, err)
+	}
+	for i, name := range []string{"file.bin", "file2.bin"} {
+		if files[i].Err != nil ||
+			files[i].Path != filepath.Join(dirTemp, name) {
+			t.Errorf("Got: %+v", files[i])
+		}
+	}
+}
+
+func TestParseCollision(t *testing.T) {
+	in := "begin 644 same.txt\n#86)C\n`\nend\nbegin 644 same.txt\n#9&5F\n`\nend\n"
+	tsts := []struct {
+		policy  uuutil.CollisionPolicy
+		second  string
+		content string
+		isErr   bool
+	}{
+		{uuutil.CollisionNone, "same.txt", "def", false},
+		{uuutil.CollisionOverwrite, "same.txt", "def", false},
+		{uuutil.CollisionError, "", "abc", true},
+		{uuutil.CollisionSkip, "", "abc", false},
+		{uuutil.CollisionRename, "same(1).txt", "abc", false},
+	}
+	for _, tst := range tsts {
+		func() {
+			defer os.RemoveAll(dirTemp)
+			files, err := uuutil.Parse(context.TODO(), nil, dirTemp,
+				bytes.NewBufferString(in), uuutil.WithCollision(tst.policy))
+			if err != nil || len(files) != 2 {
+				t.Fatal("Got: ", files, err)
+			}
+			ef := files[1]
+			if files[0].Collision != uuutil.CollisionNone {
+				t.Errorf("%d Got: %+v", tst.policy, files[0])
+			}
+			want := tst.policy
+			if want == uuutil.CollisionNone {
+				want = uuutil.CollisionOverwrite
+			}
+			if ef.Collision != want || (ef.Err != nil) != tst.isErr ||
+				tst.isErr && !os.IsExist(ef.Err) {
+				t.Errorf("%d Got: %+v", tst.policy, ef)
+			}
+			wantPath := ""
+			if tst.second != "" {
+				wantPath = filepath.Join(dirTemp, tst.second)
+			}
+			if ef.Path != wantPath {
+				t.Errorf("%d Got path: %s", tst.policy, ef.Path)
+			}
+			b, err := ioutil.ReadFile(filepath.Join(dirTemp, "same.txt"))
+			if err != nil || string(b) != tst.content {
+				t.Errorf("%d Got content: %s %v", tst.policy, b, err)
+			}
+		}()
+	}
+}
+
+func TestParseApplyMode(t *testing.T) {
+	in := "begin 600 a.txt\n#86)C\n`\nend\nbegin 4755 b.txt\n#86)C\n`\nend\n" +
+		"begin 644 c.txt\n#86)C\n`\nend\n"
+	tsts := []struct {
+		opts []uuutil.ParseOption
+		want []os.FileMode
+	}{
+		{nil, []os.FileMode{0644, 0644, 0644}},
+		{[]uuutil.ParseOption{uuutil.WithApplyMode(true)},
+			[]os.FileMode{0600, 0755, 0644}},
+		{[]uuutil.ParseOption{uuutil.WithApplyMode(true),
+			uuutil.WithModeMask(os.ModePerm | os.ModeSetuid)},
+			[]os.FileMode{0600, 0755 | os.ModeSetuid, 0644}},
+	}
+	for i, tst := range tsts {
+		func() {
+			defer os.RemoveAll(dirTemp)
+			files, err := uuutil.Parse(context.TODO(), nil, dirTemp,
+				bytes.NewBufferString(in), tst.opts...)
+			if err != nil || len(files) != 3 {
+				t.Fatal("Got: ", files, err)
+			}
+			for j, ef := range files {
+				fi, err := os.Stat(ef.Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := fi.Mode() & (os.ModePerm | os.ModeSetuid)
+				if got != tst.want[j] {
+					t.Errorf("%d %s Want: %v Got: %v", i, ef.Name, tst.want[j],
+						got)
+				}
+			}
+		}()
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	in := "begin 644 a.txt\n#86)C\n`\nend\nbegin 644 b.txt\n&86)C9&5F\n`\nend\n" +
+		"begin 644 c.txt\n#86)C\n`\nend\n"
+	tsts := []struct {
+		opts  []uuutil.ParseOption
+		sizes []int64
+		total bool
+	}{
+		{[]uuutil.ParseOption{uuutil.WithMaxFileSize(3)}, []int64{3, 0, 3},
+			false},
+		{[]uuutil.ParseOption{uuutil.WithMaxTotalSize(8)}, []int64{3, 0},
+			true},
+		{[]uuutil.ParseOption{uuutil.WithMaxFileSize(6),
+			uuutil.WithMaxTotalSize(12)}, []int64{3, 6, 3}, false},
+	}
+	for i, tst := range tsts {
+		func() {
+			defer os.RemoveAll(dirTemp)
+			files, err := uuutil.Parse(context.TODO(), nil, dirTemp,
+				bytes.NewBufferString(in), tst.opts...)
+			if len(files) != len(tst.sizes) {
+				t.Fatalf("%d Got: %+v %v", i, files, err)
+			}
+			var qe *uuutil.QuotaError
+			if tst.total {
+				if qe, _ = err.(*uuutil.QuotaError); qe == nil || !qe.Total ||
+					qe.Name != "b.txt" || !errors.Is(err, uuutil.ErrQuota) {
+					t.Errorf("%d Got error: %v", i, err)
+				}
+			} else if err != nil {
+				t.Errorf("%d Got error: %v", i, err)
+			}
+			for j, ef := range files {
+				if ef.Size != tst.sizes[j] {
+					t.Errorf("%d %s Got size: %d", i, ef.Name, ef.Size)
+				}
+				_, serr := os.Stat(filepath.Join(dirTemp, ef.Name))
+				if ef.Size == 0 && (!errors.Is(ef.Err, uuutil.ErrQuota) ||
+					ef.Path != "" || !os.IsNotExist(serr)) {
+					t.Errorf("%d Got: %+v %v", i, ef, serr)
+				}
+			}
+		}()
+	}
+}
+
+// memFile is the file of memFS.
+type memFile struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (f *memFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+// memFS is in-memory uuutil.FS.
+type memFS map[string]*memFile
+
+func (fs memFS) CreateFile(name string) (io.WriteCloser, error) {
+	if name == "bad.txt" {
+		return nil, errors.New("refused")
+	}
+	f := &memFile{}
+	fs[name] = f
+	return f, nil
+}
+
+func TestParseFS(t *testing.T) {
+	in := "begin 644 ../a.txt\n#86)C\n`\nend\nbegin 644 bad.txt\n#86)C\n`\nend\n" +
+		"begin 644 b.txt\n&86)C9&5F\n`\nend\n"
+	fs := memFS{}
+	files, err := uuutil.Parse(context.TODO(), nil, "not-used",
+		bytes.NewBufferString(in), uuutil.WithFS(fs),
+		uuutil.WithMaxFileSize(3))
+	if err != nil || len(files) != 3 {
+		t.Fatal("Got: ", files, err)
+	}
+	if files[0].Path != "a.txt" || files[0].Size != 3 || files[0].Err != nil ||
+		files[1].Path != "" || files[1].Err == nil ||
+		files[2].Path != "b.txt" || !errors.Is(files[2].Err, uuutil.ErrQuota) {
+		t.Errorf("Got: %+v", files)
+	}
+	if f := fs["a.txt"]; f == nil || f.String() != "abc" || !f.closed {
+		t.Errorf("Got a.txt: %+v", f)
+	}
+	if f := fs["b.txt"]; f == nil || !f.closed {
+		t.Errorf("Got b.txt: %+v", f)
+	}
+	if _, err := os.Stat("not-used"); !os.IsNotExist(err) {
+		t.Error("Directory is created: ", err)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	in := "begin 644 a.jpg\n#86)C\n`\nend\nbegin 644 b.txt\n#86)C\n`\nend\n" +
+		"begin 600 c.jpg\n#9&5F\n`\nend\n"
+	m, files, err := uuutil.ParseToMap(context.TODO(),
+		bytes.NewBufferString(in), uuutil.WithFilter(func(h uu.Header) bool {
+			return filepath.Ext(h.Name) == ".jpg"
+		}))
+	if err != nil || len(files) != 2 || files[0].Name != "a.jpg" ||
+		files[1].Name != "c.jpg" {
+		t.Fatal("Got: ", files, err)
+	}
+	if len(m) != 2 || string(m["a.jpg"]) != "abc" ||
+		string(m["c.jpg"]) != "def" {
+		t.Errorf("Got: %q", m)
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	in := "begin 644 a.txt\n#86)C\n`\nend\nbegin 644 b.txt\n&86)C9&5F\n`\nend\n" +
+		"begin 644 c.txt\n#86)C\n`\nend\n"
+	var got []uuutil.ParseProgress
+	_, _, err := uuutil.ParseToMap(context.TODO(), bytes.NewBufferString(in),
+		uuutil.WithFilter(func(h uu.Header) bool { return h.Name != "b.txt" }),
+		uuutil.WithProgress(func(p uuutil.ParseProgress) {
+			got = append(got, p)
+		}))
+	if err != nil || len(got) == 0 {
+		t.Fatal("Got: ", got, err)
+	}
+	for i, p := range got {
+		if i > 0 && (p.In < got[i-1].In || p.Out < got[i-1].Out ||
+			p.Parts < got[i-1].Parts) {
+			t.Errorf("Not increasing: %+v", got)
+		}
+	}
+	last := got[len(got)-1]
+	if last.Name != "c.txt" || last.In != int64(len(in)) || last.Out != 12 ||
+		last.Parts != 3 {
+		t.Errorf("Got: %+v", got)
+	}
 }
@@ -0,0 +1,90 @@
+package uuutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	uu "github.com/sanylcs/uuencode"
+)
+
+// ErrMismatch indicates the decoded content differs from the original.
+var ErrMismatch = errors.New("uuutil: decoded content does not match")
+
+// MismatchError indicates the decoded content differs from the original at
+// Offset. It wraps ErrMismatch.
+type MismatchError struct {
+	// Offset is the first differing byte. It is the length of the shorter one
+	// if one is the prefix of the other.
+	Offset int64
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("uuutil: decoded content does not match at offset %d",
+		e.Offset)
+}
+
+// Unwrap returns ErrMismatch.
+func (e *MismatchError) Unwrap() error {
+	return ErrMismatch
+}
+
+// Verify decodes the first uuencoded content of encoded and compares it with
+// original byte by byte, eg: to prove the uuencoded output is restorable
+// before deleting the sources. It returns *MismatchError if they differ.
+func Verify(encoded, original io.Reader) error {
+	p, err := uu.NewPartReader(encoded).NextPart()
+	if err == io.EOF {
+		return uu.ErrBadUUDec
+	} else if err != nil {
+		return err
+	}
+	const size = 32 * 1024
+	b1, b2 := make([]byte, size), make([]byte, size)
+	var off int64
+	for {
+		n1, err1 := io.ReadFull(p, b1)
+		if err1 != nil && err1 != io.EOF && err1 != io.ErrUnexpectedEOF {
+			return err1
+		}
+		n2, err2 := io.ReadFull(original, b2)
+		if err2 != nil && err2 != io.EOF && err2 != io.ErrUnexpectedEOF {
+			return err2
+		}
+		n := n1
+		if n2 < n {
+			n = n2
+		}
+		if !bytes.Equal(b1[:n], b2[:n]) {
+			i := 0
+			for b1[i] == b2[i] {
+				i++
+			}
+			return &MismatchError{Offset: off + int64(i)}
+		}
+		if n1 != n2 {
+			return &MismatchError{Offset: off + int64(n)}
+		} else if n1 < size {
+			return nil
+		}
+		off += int64(n)
+	}
+}
+
+// VerifyFile is like Verify but reads the uuencoded file encoded and the
+// original file.
+func VerifyFile(encoded, original string) error {
+	fe, err := os.Open(encoded)
+	if err != nil {
+		return err
+	}
+	defer fe.Close()
+	fo, err := os.Open(original)
+	if err != nil {
+		return err
+	}
+	defer fo.Close()
+	return Verify(fe, fo)
+}
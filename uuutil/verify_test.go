@@ -0,0 +1,60 @@
+package uuutil_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	uu "github.com/sanylcs/uuencode"
+	"github.com/sanylcs/uuencode/uuutil"
+)
+
+func TestVerify(t *testing.T) {
+	enc := "junk\nbegin 644 a.txt\n&86)C9&5F\n`\nend\n"
+	tsts := []struct {
+		enc, orig string
+		offset    int64
+		err       error
+	}{
+		{enc, "abcdef", -1, nil},
+		{enc, "abcxef", 3, uuutil.ErrMismatch},
+		{enc, "abcdefg", 6, uuutil.ErrMismatch},
+		{enc, "abc", 3, uuutil.ErrMismatch},
+		{"no uuencode\n", "", -1, uu.ErrBadUUDec},
+		{"begin 644 a.txt\n&86)C9&5F\n", "abcdef", -1, uu.ErrBadUUDec},
+	}
+	for i, tst := range tsts {
+		err := uuutil.Verify(strings.NewReader(tst.enc),
+			strings.NewReader(tst.orig))
+		if !errors.Is(err, tst.err) || tst.err == nil && err != nil {
+			t.Errorf("%d Got: %v", i, err)
+		}
+		var me *uuutil.MismatchError
+		if errors.As(err, &me) && me.Offset != tst.offset {
+			t.Errorf("%d Got offset: %d", i, me.Offset)
+		}
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	p := filepath.Join(tstFolder, tConvert, testConvertFiles[0])
+	enc := new(bytes.Buffer)
+	if err := uuutil.Convert(enc, true, "\n", p+"_1.in"); err != nil {
+		t.Fatal(err)
+	}
+	orig := readInputFile(tConvert, testConvertFiles[0]+"_1")
+	defer orig.Close()
+	if err := uuutil.Verify(enc, orig); err != nil {
+		t.Error(err)
+	}
+	err := uuutil.VerifyFile(filepath.Join(tstFolder, tConvert,
+		testConvertFiles[0]+".out"), p+"_2.in")
+	if !errors.Is(err, uuutil.ErrMismatch) {
+		t.Error("Got: ", err)
+	}
+	if err = uuutil.VerifyFile("unknown file", p+"_1.in"); err == nil {
+		t.Error("Expected error but return nil")
+	}
+}
@@ -0,0 +1,67 @@
+package uuutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/net/context"
+)
+
+// zipFile buffers the content until Close, so the failed content is dropped
+// by abort instead of left as partial entry.
+type zipFile struct {
+	bytes.Buffer
+	zw *zip.Writer
+	fh *zip.FileHeader
+}
+
+func (f *zipFile) Close() error {
+	w, err := f.zw.CreateHeader(f.fh)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(f.Bytes())
+	return err
+}
+
+func (f *zipFile) abort() {
+	f.Reset()
+}
+
+// zipFS is FS that writes files as the entries of zip archive.
+type zipFS struct {
+	zw *zip.Writer
+	// unnamed is the number of contents without file name.
+	unnamed int
+}
+
+func (z *zipFS) CreateFile(name string) (io.WriteCloser, error) {
+	w, _, err := z.createFileMode(name, 0644)
+	return w, err
+}
+
+func (z *zipFS) createFileMode(name string, mode os.FileMode) (io.WriteCloser,
+	string, error) {
+	if name == "" {
+		z.unnamed++
+		name = fmt.Sprintf("uu_%d", z.unnamed)
+	}
+	fh := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	fh.SetMode(mode)
+	return &zipFile{zw: z.zw, fh: fh}, name, nil
+}
+
+// ParseToZip is like Parse but writes every decoded content as the entry of
+// zw, with the file name and permission bits of the begin line. Content without
+// file name is named uu_1, uu_2 and so on. The bytes outside uuencoded contents
+// are dropped. Each content is buffered in memory until it ends, so the content
+// that fails decoding or exceeds WithMaxFileSize is not written into zw. zw is
+// not closed.
+func ParseToZip(ctx context.Context, zw *zip.Writer, r io.Reader,
+	opts ...ParseOption) ([]ExtractedFile, error) {
+	opts = append(opts, WithFS(&zipFS{zw: zw}))
+	return Parse(ctx, nil, "", r, opts...)
+}
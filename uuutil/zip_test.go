@@ -0,0 +1,81 @@
+package uuutil_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sanylcs/uuencode/uuutil"
+	"golang.org/x/net/context"
+)
+
+func TestParseToZip(t *testing.T) {
+	in := "junk\nbegin 600 a.txt\n#86)C\n`\nend\nbegin 4755 ../b.txt\n" +
+		"&86)C9&5F\n`\nend\nbegin 644\n#86)C\n`\nend\n"
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files, err := uuutil.ParseToZip(context.TODO(), zw,
+		bytes.NewBufferString(in))
+	if err != nil || len(files) != 3 || files[2].Path != "uu_1" {
+		t.Fatal("Got: ", files, err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name    string
+		mode    os.FileMode
+		content string
+	}{
+		{"a.txt", 0600, "abc"},
+		{"b.txt", 0755, "abcdef"},
+		{"uu_1", 0644, "abc"},
+	}
+	if len(zr.File) != len(want) {
+		t.Fatal("Got entries: ", len(zr.File))
+	}
+	for i, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil || f.Name != want[i].name || f.Mode() != want[i].mode ||
+			string(b) != want[i].content {
+			t.Errorf("Got: %s %v %q %v", f.Name, f.Mode(), b, err)
+		}
+	}
+}
+
+func TestParseToZipFailed(t *testing.T) {
+	in := "begin 644 a.txt\n#86)C\n`\nend\nbegin 644 big.txt\n$86)C9```\n`\n" +
+		"end\nbegin 644 bad.txt\n#86)C\n#86)\n`\nend\n"
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files, _ := uuutil.ParseToZip(context.TODO(), zw,
+		bytes.NewBufferString(in), uuutil.WithMaxFileSize(3))
+	if len(files) != 3 || files[1].Err == nil || files[2].Err == nil ||
+		files[1].Path != "" || files[2].Path != "" {
+		t.Fatal("Got: ", files)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the good content is in the archive.
+	if len(zr.File) != 1 || zr.File[0].Name != "a.txt" {
+		for _, f := range zr.File {
+			t.Error("Got entry: ", f.Name)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package uuencode
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/text/transform"
+)
+
+// Report is the result of Validate.
+type Report struct {
+	// Sections are the uuencoded contents in input order.
+	Sections []SectionReport
+	// Violations are the problems found in input order.
+	Violations []Violation
+}
+
+// SectionReport describes one uuencoded content found by Validate.
+type SectionReport struct {
+	// Header is the parsed begin line.
+	Header Header
+	// Line and Offset locate the begin line, they have the same meaning as in
+	// DecodeError.
+	Line   int
+	Offset int64
+	// Lines is the number of valid body lines, without the grave and end
+	// marker lines.
+	Lines int
+	// Size is the length in bytes of the decoded content.
+	Size int64
+	// HasEnd reports whether the end marker line is found.
+	HasEnd bool
+}
+
+// Violation is a problem found by Validate.
+type Violation struct {
+	// Line, Offset and Part has the same meaning as in DecodeError.
+	Line   int
+	Offset int64
+	Part   int
+	// Reason describes the problem.
+	Reason string
+	// Text is the input line that causes the problem, if any.
+	Text string
+	// Err is the *DecodeError that stops the validation. It is nil for
+	// problems that decoding recovers from, eg: skipped junk lines.
+	Err error
+}
+
+// Validate reads r through and reports the uuencoded contents and the problems
+// found without outputting any decoded bytes. Invalid body lines are skipped
+// like WithLenient, so all problems of a content are reported, but the
+// problems that stop the decoding, eg: missing end marker line, end the
+// validation. Only errors of reading r are returned.
+func Validate(r io.Reader) (*Report, error) {
+	rep := new(Report)
+	d, cancel, ch := NewMultiDecodeWith(WithLenient(true))
+	d.OnTrace(func(e TraceEvent) {
+		switch e.Kind {
+		case TraceHeader:
+			rep.Sections = append(rep.Sections, SectionReport{Line: e.Line,
+				Offset: e.Offset, Header: parseHeader([]byte(e.Text))})
+		case TraceLine:
+			rep.Sections[len(rep.Sections)-1].Lines++
+		case TracePartClose:
+			// the grave and end marker lines are traced too.
+			s := &rep.Sections[len(rep.Sections)-1]
+			s.Lines -= 2
+			s.HasEnd = true
+		}
+	})
+	d.OnWarning(func(w Warning) {
+		rep.Violations = append(rep.Violations, Violation{Line: w.Line,
+			Offset: w.Offset, Part: w.Part, Reason: w.Reason, Text: w.Text})
+	})
+	sizes := make(chan []int64, 1)
+	go func() {
+		var ns []int64
+		for rc := range ch {
+			// the failure is reported by Transform.
+			n, _ := io.Copy(ioutil.Discard, rc)
+			ns = append(ns, n)
+		}
+		sizes <- ns
+	}()
+	_, err := io.Copy(ioutil.Discard, transform.NewReader(r, d))
+	// release the consumer of the unfinished content on reading failure.
+	cancel()
+	d.Close()
+	for i, n := range <-sizes {
+		rep.Sections[i].Size = n
+	}
+	var derr *DecodeError
+	if !errors.As(err, &derr) {
+		return rep, err
+	}
+	v := Violation{Line: derr.Line, Offset: derr.Offset, Part: derr.Part,
+		Reason: derr.Err.Error(), Err: derr}
+	var (
+		lerr *BadLineError
+		merr *MissingEndError
+		herr *HeaderError
+	)
+	switch {
+	case errors.As(derr, &lerr):
+		v.Reason, v.Text = lerr.Reason, lerr.Line
+	case errors.As(derr, &merr):
+		v.Text = merr.Line
+	case errors.As(derr, &herr):
+		v.Text = herr.Raw
+	}
+	rep.Violations = append(rep.Violations, v)
+	return rep, nil
+}
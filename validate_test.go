@@ -0,0 +1,60 @@
+package uuencode_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/sanylcs/uuencode"
+)
+
+func TestValidate(t *testing.T) {
+	in := "text\nbegin 644 a.txt\n#0V%T\n\n#0V%T\n`\nend\n" +
+		"begin 600 b.txt\n#1&]G\n"
+	rep, err := uuencode.Validate(bytes.NewBufferString(in))
+	if err != nil {
+		t.Fatal("Expecting non-error but got err:", err)
+	}
+	if n := len(rep.Violations); n == 0 ||
+		!errors.Is(rep.Violations[n-1].Err, uuencode.ErrMissingEnd) {
+		t.Fatal("Got violations:", rep.Violations)
+	}
+	rep.Violations[len(rep.Violations)-1].Err = nil
+	want := &uuencode.Report{
+		Sections: []uuencode.SectionReport{
+			{Header: uuencode.Header{Name: "a.txt", Mode: 0644,
+				Raw: "begin 644 a.txt"}, Line: 2, Offset: 5, Lines: 2,
+				Size: 6, HasEnd: true},
+			{Header: uuencode.Header{Name: "b.txt", Mode: 0600,
+				Raw: "begin 600 b.txt"}, Line: 8, Offset: 40, Lines: 1,
+				Size: 3},
+		},
+		Violations: []uuencode.Violation{
+			{Line: 4, Offset: 27, Part: 0, Reason: "empty line"},
+			{Line: 10, Offset: 62, Part: 1,
+				Reason: "uuencode: missing end marker"},
+		},
+	}
+	if diff := pretty.Compare(rep, want); diff != "" {
+		t.Errorf("Diff: %s", diff)
+	}
+}
+
+func TestValidateReadError(t *testing.T) {
+	errRead := errors.New("read failure")
+	r := io.MultiReader(bytes.NewBufferString("begin 644 a\n#0V%T\n"),
+		&tstErrReader{errRead})
+	rep, err := uuencode.Validate(r)
+	if err != errRead {
+		t.Error("Got: ", err, " Expecting: ", errRead)
+	}
+	if len(rep.Sections) != 1 || len(rep.Violations) != 0 {
+		t.Error("Got report:", rep)
+	}
+}
+
+type tstErrReader struct{ err error }
+
+func (r *tstErrReader) Read([]byte) (int, error) { return 0, r.err }